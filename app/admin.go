@@ -0,0 +1,205 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/proactiongo/pagocore/metrics"
+	log "github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// adminHostDft is the admin server bind host if none is set, the admin server must never be public
+const adminHostDft = "127.0.0.1"
+
+// processStart is a process start time used for uptime
+var processStart = time.Now()
+
+// RuntimeStats is a Go runtime stats report
+type RuntimeStats struct {
+	GoVersion    string        `json:"go_version"`
+	NumCPU       int           `json:"num_cpu"`
+	GOMAXPROCS   int           `json:"gomaxprocs"`
+	Goroutines   int           `json:"goroutines"`
+	Uptime       time.Duration `json:"uptime"`
+	HeapAlloc    uint64        `json:"heap_alloc"`
+	HeapSys      uint64        `json:"heap_sys"`
+	HeapObjects  uint64        `json:"heap_objects"`
+	TotalAlloc   uint64        `json:"total_alloc"`
+	Sys          uint64        `json:"sys"`
+	NumGC        uint32        `json:"num_gc"`
+	PauseTotalNs uint64        `json:"pause_total_ns"`
+}
+
+// LogLevelInput is a log level switch request
+type LogLevelInput struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// adminHook returns the App hook serving admin endpoints on Config.AdminHost and Config.AdminPort
+func (a *App) adminHook() Hook {
+	var srv *http.Server
+	srvErr := make(chan error, 1)
+
+	return Hook{
+		Name: "admin server",
+		OnStart: func(ctx context.Context, ctn *di.Container) error {
+			conf := DIGetConfig(ctn)
+			srv = &http.Server{
+				Addr:              adminAddr(conf),
+				Handler:           NewAdminRouter(ctn),
+				ReadHeaderTimeout: conf.ReadHeaderTimeout,
+				IdleTimeout:       conf.IdleTimeout,
+			}
+
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+
+			log.Info(logTag, "starting admin server on ", srv.Addr)
+			go func() {
+				srvErr <- srv.Serve(ln)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context, ctn *di.Container) error {
+			err := srv.Shutdown(ctx)
+			if err != nil {
+				// in-flight requests must be aborted before the DI container is closed
+				log.Error(logTag, "failed to shutdown admin server gracefully: ", err)
+				_ = srv.Close()
+				<-srvErr
+				return err
+			}
+			err = <-srvErr
+			if !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			log.Info(logTag, "admin server stopped")
+			return nil
+		},
+	}
+}
+
+// adminAddr returns the admin server address, the host falls back to adminHostDft if the config is not loaded with tags
+func adminAddr(conf *Config) string {
+	host := conf.AdminHost
+	if host == "" {
+		host = adminHostDft
+	}
+	return net.JoinHostPort(host, conf.AdminPort)
+}
+
+// NewAdminRouter creates the router with diagnostic endpoints:
+// pprof under /debug/pprof/, metrics, runtime stats, DI dependencies, effective config and log level switch.
+// It must never be exposed on the public port.
+func NewAdminRouter(ctn *di.Container) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	debug := router.Group("/debug/pprof")
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+	debug.GET("/:profile", func(c *gin.Context) {
+		pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+	})
+
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/runtime", func(c *gin.Context) {
+		c.JSON(http.StatusOK, GetRuntimeStats())
+	})
+	router.GET("/di", func(c *gin.Context) {
+		c.JSON(http.StatusOK, ctn.Graph())
+	})
+	router.GET("/config", func(c *gin.Context) {
+		c.JSON(http.StatusOK, ConfigDump(ctn))
+	})
+	router.GET("/loglevel", func(c *gin.Context) {
+		c.JSON(http.StatusOK, &LogLevelInput{Level: log.GetLevel().String()})
+	})
+	router.PUT("/loglevel", func(c *gin.Context) {
+		in := &LogLevelInput{}
+		err := c.ShouldBindJSON(in)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, pagocore.NewError(http.StatusBadRequest, err))
+			return
+		}
+		lvl, err := log.ParseLevel(in.Level)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, pagocore.NewError(http.StatusUnprocessableEntity, err))
+			return
+		}
+		log.SetLevel(lvl)
+		log.Info(logTag, "log level switched to ", lvl)
+		c.JSON(http.StatusOK, &LogLevelInput{Level: lvl.String()})
+	})
+
+	return router
+}
+
+// GetRuntimeStats returns current Go runtime stats
+func GetRuntimeStats() *RuntimeStats {
+	mem := &runtime.MemStats{}
+	runtime.ReadMemStats(mem)
+	return &RuntimeStats{
+		GoVersion:    runtime.Version(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		Goroutines:   runtime.NumGoroutine(),
+		Uptime:       time.Since(processStart),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapSys:      mem.HeapSys,
+		HeapObjects:  mem.HeapObjects,
+		TotalAlloc:   mem.TotalAlloc,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		PauseTotalNs: mem.PauseTotalNs,
+	}
+}
+
+// ConfigDump returns effective values of the service config from the DI container with secrets masked.
+// Sources are set if DIConfigLoader is registered.
+func ConfigDump(ctn *di.Container) []*pagocore.ConfigValue {
+	var loader *pagocore.ConfigLoader
+	if ctn.Has(DIConfigLoader) {
+		loader = DIGetConfigLoader(ctn)
+	}
+
+	fields, err := configFields(DIGetServiceConfig(ctn))
+	if err != nil {
+		return nil
+	}
+	if loader != nil {
+		keys := make([]string, len(fields))
+		for i, f := range fields {
+			keys[i] = f.key
+		}
+		loader.TrackKeys(keys...)
+	}
+	values := make([]*pagocore.ConfigValue, len(fields))
+	for i, f := range fields {
+		val := f.string()
+		if f.secret && val != "" {
+			val = secretMask
+		}
+		values[i] = &pagocore.ConfigValue{
+			Key:   f.key,
+			Value: val,
+		}
+		if loader != nil {
+			values[i].Source = loader.Source(f.key)
+		}
+	}
+	return values
+}
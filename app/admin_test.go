@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApp_AdminServer(t *testing.T) {
+	defer log.SetLevel(log.GetLevel())
+
+	conf := &Config{
+		Port:            getFreePort(t),
+		ShutdownTimeout: time.Second,
+		AdminHost:       "127.0.0.1",
+		AdminPort:       getFreePort(t),
+		JWTPassword:     []byte("jwt_secret"),
+		MetricsPath:     "/metrics",
+	}
+	stop := runTestApp(t, conf)
+	defer stop()
+	waitForPort(t, conf.AdminPort)
+
+	admin := "http://127.0.0.1:" + conf.AdminPort
+
+	stats := &RuntimeStats{}
+	assert.Equal(t, http.StatusOK, getJSON(t, admin+"/runtime", stats))
+	assert.NotZero(t, stats.Goroutines)
+	assert.NotEmpty(t, stats.GoVersion)
+
+	graph := &di.Graph{}
+	assert.Equal(t, http.StatusOK, getJSON(t, admin+"/di", graph))
+	if assert.NotEmpty(t, graph.Nodes) {
+		assert.Equal(t, DIConfig, graph.Nodes[0].Name)
+		assert.True(t, graph.Nodes[0].Built)
+	}
+
+	var values []*pagocore.ConfigValue
+	assert.Equal(t, http.StatusOK, getJSON(t, admin+"/config", &values))
+	found := false
+	for _, v := range values {
+		if v.Key == "jwt_password" {
+			found = true
+			assert.Equal(t, secretMask, v.Value)
+		}
+	}
+	assert.True(t, found)
+
+	resp, err := http.Get(admin + "/debug/pprof/goroutine?debug=1")
+	if assert.NoError(t, err) {
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp, err = http.Get("http://127.0.0.1:" + conf.Port + "/debug/pprof/")
+	if assert.NoError(t, err) {
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, "admin endpoints must not be served on the public port")
+	}
+
+	for _, url := range []string{admin + "/metrics", "http://127.0.0.1:" + conf.Port + "/metrics"} {
+		resp, err = http.Get(url)
+		if assert.NoError(t, err) {
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), "di_build_duration_seconds")
+		}
+	}
+
+	lvl := &LogLevelInput{}
+	assert.Equal(t, http.StatusOK, putJSON(t, admin+"/loglevel", `{"level":"trace"}`, lvl))
+	assert.Equal(t, "trace", lvl.Level)
+	assert.Equal(t, log.TraceLevel, log.GetLevel())
+	assert.Equal(t, http.StatusUnprocessableEntity, putJSON(t, admin+"/loglevel", `{"level":"loud"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, putJSON(t, admin+"/loglevel", `{}`, nil))
+}
+
+// getJSON makes GET request and decodes JSON response to v
+func getJSON(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp.StatusCode
+}
+
+// putJSON makes PUT request with JSON body and decodes JSON response to v if it is not nil
+func putJSON(t *testing.T, url, body string, v interface{}) int {
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if v != nil {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func TestApp_adminAddr(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:9090", adminAddr(&Config{AdminPort: "9090"}))
+	assert.Equal(t, "10.0.0.1:9090", adminAddr(&Config{AdminHost: "10.0.0.1", AdminPort: "9090"}))
+	assert.Equal(t, "[::1]:9090", adminAddr(&Config{AdminHost: "::1", AdminPort: "9090"}))
+}
+
+func TestApp_adminHook_ShutdownTimeout(t *testing.T) {
+	conf := &Config{AdminPort: getFreePort(t)}
+	ctn := buildTestContainer(t, conf)
+	hook := NewApp(ctn).adminHook()
+	if !assert.NoError(t, hook.OnStart(context.Background(), ctn)) {
+		return
+	}
+	waitForPort(t, conf.AdminPort)
+
+	reqErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://127.0.0.1:" + conf.AdminPort + "/debug/pprof/profile?seconds=5")
+		if err == nil {
+			_, err = io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+		}
+		reqErr <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, hook.OnStop(ctx, ctn), context.DeadlineExceeded)
+	assert.Error(t, <-reqErr, "in-flight request must be aborted")
+	assert.Less(t, time.Since(start), time.Second)
+}
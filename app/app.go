@@ -1,10 +1,22 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
 	"github.com/proactiongo/pagocore/di"
 	"github.com/proactiongo/pagocore/ginsrv"
+	"github.com/proactiongo/pagocore/metrics"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/netutil"
+	"net"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
 )
 
 const logTag = "[pagocore.App] "
@@ -33,53 +45,207 @@ type App struct {
 	prepareCtnFn    PrepareContainerFn
 	prepareRouterFn PrepareRouterFn
 
+	hooks             []Hook
+	modules           []Module
+	configSubscribers []ConfigSubscriber
+	jobs              []Job
+	jobLocker         JobLocker
+
+	// reloadMu serializes config and secrets reloads
+	reloadMu sync.Mutex
+
 	initialized bool
+	initErr     error
 }
 
-// Init initializes App without starting the server
-func (a *App) Init() {
+// Init initializes App without starting the server.
+// The router can't be initialized twice, so a failed Init is not retried and its error is returned by the next calls.
+func (a *App) Init() error {
 	if a.initialized {
-		return
+		return a.initErr
 	}
 	a.initialized = true
+	a.initErr = a.init()
+	return a.initErr
+}
 
+// init prepares the DI container, mounts modules and routes
+func (a *App) init() error {
 	if a.prepareCtnFn != nil {
 		err := a.prepareCtnFn(a.C())
 		if err != nil {
-			log.Fatal(logTag, "failed to prepare DI container: ", err)
+			log.Error(logTag, "failed to prepare DI container: ", err)
+			return err
 		}
 	}
 
+	conf := DIGetConfig(a.C())
+	metrics.ObserveContainer(a.C())
+
 	router := DIGetRouter(a.C())
-	router.Use(ginsrv.M().SetDIContainer(a.C()))
+	router.Use(ginsrv.M().Tracing(), ginsrv.M().SetDIContainer(a.C()), ginsrv.M().Metrics())
+	err := a.mountModules(router)
+	if err != nil {
+		return err
+	}
 
 	if a.prepareRouterFn != nil {
 		err := a.prepareRouterFn(router, a.C())
 		if err != nil {
-			log.Fatal(logTag, "failed to init router: ", err)
+			log.Error(logTag, "failed to init router: ", err)
+			return err
 		}
 	}
+
+	if conf.MetricsPath != "" {
+		registerRouteIfMissing(router, definedGETRoutes(router), conf.MetricsPath, gin.WrapH(metrics.Handler()))
+	}
+
+	if !pagocore.Opt.HealthRoutesDisabled {
+		registerHealthRoutes(router, pagocore.Opt.GetHealthBasePath())
+	}
+
+	if conf.AdminPort != "" {
+		a.AddHooks(a.adminHook())
+	}
+
+	return nil
+}
+
+// Run starts the App's server and blocks until SIGINT or SIGTERM is received
+func (a *App) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return a.RunContext(ctx)
 }
 
-// Run starts the App's server
-func (a *App) Run() {
-	a.Init()
-	defer a.Close()
+// RunContext starts the App's hooks and server and blocks until ctx is done.
+// On shutdown the server stops accepting new connections, waits for in-flight
+// requests for Config.ShutdownTimeout, then the hooks are stopped and the DI container is closed.
+func (a *App) RunContext(ctx context.Context) error {
+	err := a.run(ctx)
+	closeErr := a.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// run initializes the App and runs hooks and server until ctx is done
+func (a *App) run(ctx context.Context) error {
+	err := a.Init()
+	if err != nil {
+		return err
+	}
+
+	err = a.startHooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = a.serve(ctx)
+	stopErr := a.stopHooks(a.hooks)
+	if err != nil {
+		return err
+	}
+	return stopErr
+}
+
+// serve runs the http server until ctx is done
+func (a *App) serve(ctx context.Context) error {
+	conf := DIGetConfig(a.C())
+	srv := &http.Server{
+		Addr:              ":" + conf.Port,
+		Handler:           DIGetRouter(a.C()),
+		ReadHeaderTimeout: conf.ReadHeaderTimeout,
+		ReadTimeout:       conf.ReadTimeout,
+		WriteTimeout:      conf.WriteTimeout,
+		IdleTimeout:       conf.IdleTimeout,
+		MaxHeaderBytes:    conf.MaxHeaderBytes,
+	}
+
+	if conf.IsTLSEnabled() {
+		tlsConf, stopReload, err := newServerTLSConfig(conf)
+		if err != nil {
+			log.Error(logTag, "failed to init tls: ", err)
+			return err
+		}
+		defer stopReload()
+		srv.TLSConfig = tlsConf
+	} else if conf.H2C {
+		srv.Handler = h2c.NewHandler(srv.Handler, &http2.Server{})
+	}
+
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		log.Error(logTag, "http server failed: ", err)
+		return err
+	}
+	if conf.MaxConnections > 0 {
+		ln = netutil.LimitListener(ln, conf.MaxConnections)
+	}
+
+	srvErr := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			log.Info(logTag, "starting https server on ", srv.Addr)
+			srvErr <- srv.ServeTLS(ln, "", "")
+			return
+		}
+		log.Info(logTag, "starting http server on ", srv.Addr)
+		srvErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-srvErr:
+		log.Error(logTag, "http server failed: ", err)
+		return err
+	case <-ctx.Done():
+	}
 
-	router := DIGetRouter(a.ctn)
-	conf := DIGetConfig(a.ctn)
+	log.Info(logTag, "shutting down http server")
 
-	log.Info(logTag, "starting gin server")
+	timeout := conf.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = shutdownTimeoutDft
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	err := router.Run(":" + conf.Port)
+	err = srv.Shutdown(shutdownCtx)
 	if err != nil {
-		log.Fatal(err)
+		// in-flight requests must be aborted before the DI container is closed
+		log.Error(logTag, "failed to shutdown http server gracefully: ", err)
+		_ = srv.Close()
+		<-srvErr
+		return err
+	}
+
+	err = <-srvErr
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+
+	log.Info(logTag, "http server stopped")
+	return nil
 }
 
-// Close finalizes the App
-func (a *App) Close() {
-	a.ctn.Close()
+// Close finalizes the App closing the DI container within Config.ShutdownTimeout
+func (a *App) Close() error {
+	timeout := shutdownTimeoutDft
+	conf, err := di.SafeGet[*Config](a.C(), DIConfig)
+	if err == nil && conf != nil && conf.ShutdownTimeout > 0 {
+		timeout = conf.ShutdownTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = a.C().Close(ctx)
+	if err != nil {
+		log.Error(logTag, "failed to close DI container: ", err)
+	}
+	return err
 }
 
 // SetPrepareRouterFn sets init router hook
@@ -100,3 +266,31 @@ func (a *App) C() *di.Container {
 	}
 	return a.ctn
 }
+
+// registerHealthRoutes mounts health endpoints under basePath skipping the routes already defined by the service
+func registerHealthRoutes(router *gin.Engine, basePath string) {
+	defined := definedGETRoutes(router)
+	group := router.Group(basePath)
+	registerRouteIfMissing(group, defined, "/healthz", ginsrv.LivenessHandler)
+	registerRouteIfMissing(group, defined, "/readyz", ginsrv.ReadinessHandler)
+}
+
+// registerRouteIfMissing mounts GET route to the group if it is not in defined paths
+func registerRouteIfMissing(group gin.IRouter, defined map[string]bool, path string, handler gin.HandlerFunc) {
+	if defined[group.Group(path).BasePath()] {
+		log.Info(logTag, "route ", path, " is already defined, skipping")
+		return
+	}
+	group.GET(path, handler)
+}
+
+// definedGETRoutes returns paths of the router GET routes
+func definedGETRoutes(router *gin.Engine) map[string]bool {
+	defined := map[string]bool{}
+	for _, route := range router.Routes() {
+		if route.Method == http.MethodGet {
+			defined[route.Path] = true
+		}
+	}
+	return defined
+}
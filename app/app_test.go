@@ -0,0 +1,337 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/proactiongo/pagocore/ginsrv"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestApp_RunContext(t *testing.T) {
+	port := getFreePort(t)
+	closed := false
+
+	ctn := buildTestContainer(t, &Config{Port: port, ShutdownTimeout: 2 * time.Second}, di.Def{
+		Name: "test_closable",
+		Build: func(ctn *di.Container) (interface{}, error) {
+			return "test", nil
+		},
+		Close: func(obj interface{}) error {
+			closed = true
+			return nil
+		},
+	})
+
+	a := NewApp(ctn)
+	a.SetPrepareRouterFn(func(router *gin.Engine, ctn *di.Container) error {
+		router.GET("/slow", func(c *gin.Context) {
+			time.Sleep(300 * time.Millisecond)
+			c.String(http.StatusOK, "done")
+		})
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- a.RunContext(ctx)
+	}()
+
+	waitForPort(t, port)
+
+	resp := make(chan int, 1)
+	go func() {
+		r, err := http.Get("http://127.0.0.1:" + port + "/slow")
+		if err != nil {
+			resp <- 0
+			return
+		}
+		_ = r.Body.Close()
+		resp <- r.StatusCode
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	assert.Equal(t, http.StatusOK, <-resp)
+	assert.NoError(t, <-runErr)
+	assert.True(t, closed)
+}
+
+func TestApp_RunContext_ShutdownTimeout(t *testing.T) {
+	port := getFreePort(t)
+
+	a := NewApp(buildTestContainer(t, &Config{Port: port, ShutdownTimeout: 100 * time.Millisecond}))
+	a.SetPrepareRouterFn(func(router *gin.Engine, ctn *di.Container) error {
+		router.GET("/slow", func(c *gin.Context) {
+			time.Sleep(2 * time.Second)
+			c.String(http.StatusOK, "done")
+		})
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- a.RunContext(ctx)
+	}()
+
+	waitForPort(t, port)
+
+	reqErr := make(chan error, 1)
+	go func() {
+		r, err := http.Get("http://127.0.0.1:" + port + "/slow")
+		if err == nil {
+			_ = r.Body.Close()
+		}
+		reqErr <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	cancel()
+
+	assert.ErrorIs(t, <-runErr, context.DeadlineExceeded)
+	assert.Error(t, <-reqErr, "in-flight request must be aborted")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestApp_RunContext_ListenError(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = l.Close()
+	}()
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+
+	a := NewApp(buildTestContainer(t, &Config{Port: port, ShutdownTimeout: time.Second}))
+	assert.Error(t, a.RunContext(context.Background()))
+}
+
+func TestApp_RunContext_Limits(t *testing.T) {
+	conf := &Config{
+		Port:              getFreePort(t),
+		ShutdownTimeout:   time.Second,
+		ReadHeaderTimeout: 100 * time.Millisecond,
+		MaxConnections:    1,
+	}
+	stop := runTestApp(t, conf)
+	defer stop()
+
+	slow, err := net.Dial("tcp", "127.0.0.1:"+conf.Port)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = slow.Close()
+	}()
+	_, err = slow.Write([]byte("GET /ping HTTP/1.1\r\n"))
+	assert.NoError(t, err)
+
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	_, err = client.Get("http://127.0.0.1:" + conf.Port + "/ping")
+	assert.Error(t, err, "connections limit must be applied")
+
+	_ = slow.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadAll(slow)
+	assert.NoError(t, err, "slow connection must be closed by read header timeout")
+
+	resp, err := http.Get("http://127.0.0.1:" + conf.Port + "/ping")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// buildTestContainer builds a container with the given config, a default router and extra definitions
+func buildTestContainer(t *testing.T, conf *Config, defs ...di.Def) *di.Container {
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: DIConfig,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return conf, nil
+			},
+		},
+		di.Def{
+			Name: DIRouter,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return ginsrv.GetDefaultRouter(), nil
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.Add(defs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctn, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctn
+}
+
+// getFreePort returns a free TCP port number
+func getFreePort(t *testing.T) string {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = l.Close()
+	}()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+// waitForPort waits until the port accepts connections
+func waitForPort(t *testing.T, port string) {
+	for i := 0; i < 100; i++ {
+		conn, err := net.Dial("tcp", "127.0.0.1:"+port)
+		if err == nil {
+			_ = conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("server is not started on port ", port)
+}
+
+func TestApp_Hooks(t *testing.T) {
+	var calls []string
+	hook := func(name string, err error) HookFn {
+		return func(ctx context.Context, ctn *di.Container) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	port := getFreePort(t)
+	a := NewApp(buildTestContainer(t, &Config{Port: port, ShutdownTimeout: time.Second}))
+	a.AddHooks(
+		Hook{Name: "h1", OnStart: hook("start1", nil), OnStop: hook("stop1", nil)},
+		Hook{Name: "h2", OnStart: hook("start2", nil), OnStop: hook("stop2", nil)},
+	)
+	a.OnStop("h3", hook("stop3", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- a.RunContext(ctx)
+	}()
+	waitForPort(t, port)
+	cancel()
+	assert.NoError(t, <-runErr)
+	assert.Equal(t, []string{"start1", "start2", "stop3", "stop2", "stop1"}, calls)
+
+	calls = nil
+	a = NewApp(buildTestContainer(t, &Config{Port: getFreePort(t), ShutdownTimeout: time.Second}))
+	a.AddHooks(
+		Hook{Name: "h1", OnStart: hook("start1", nil), OnStop: hook("stop1", nil)},
+		Hook{Name: "h2", OnStart: hook("start2", errors.New("expected error")), OnStop: hook("stop2", nil)},
+		Hook{Name: "h3", OnStart: hook("start3", nil), OnStop: hook("stop3", nil)},
+	)
+	assert.Error(t, a.RunContext(context.Background()))
+	assert.Equal(t, []string{"start1", "start2", "stop1"}, calls)
+
+	a = NewApp(buildTestContainer(t, &Config{Port: getFreePort(t), ShutdownTimeout: time.Second}))
+	a.AddHooks(Hook{
+		Name:    "slow",
+		Timeout: 50 * time.Millisecond,
+		OnStart: func(ctx context.Context, ctn *di.Container) error {
+			<-ctx.Done()
+			time.Sleep(time.Second)
+			return nil
+		},
+	})
+	assert.ErrorIs(t, a.RunContext(context.Background()), context.DeadlineExceeded)
+}
+
+func TestApp_Init_HealthRoutes(t *testing.T) {
+	base := pagocore.Opt.GetHealthBasePath()
+
+	a := NewApp(buildTestContainer(t, &Config{Port: getFreePort(t)}))
+	a.SetPrepareRouterFn(func(router *gin.Engine, ctn *di.Container) error {
+		router.GET(base+"/healthz", func(c *gin.Context) {
+			c.String(http.StatusOK, "custom")
+		})
+		return nil
+	})
+	if !assert.NotPanics(t, func() { assert.NoError(t, a.Init()) }) {
+		return
+	}
+
+	router := DIGetRouter(a.C())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, base+"/healthz", nil))
+	assert.Equal(t, "custom", w.Body.String())
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, base+"/readyz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	pagocore.Opt.HealthRoutesDisabled = true
+	defer func() {
+		pagocore.Opt.HealthRoutesDisabled = false
+	}()
+	a = NewApp(buildTestContainer(t, &Config{Port: getFreePort(t)}))
+	assert.NoError(t, a.Init())
+	w = httptest.NewRecorder()
+	DIGetRouter(a.C()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, base+"/readyz", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestApp_Init_MetricsRoute(t *testing.T) {
+	v := viper.New()
+	v.Set("service_port", getFreePort(t))
+	v.Set("jwt_password", "secret")
+	conf := &Config{}
+	if !assert.NoError(t, LoadConfig(v, conf)) {
+		return
+	}
+
+	a := NewApp(buildTestContainer(t, conf))
+	assert.NoError(t, a.Init())
+	w := httptest.NewRecorder()
+	DIGetRouter(a.C()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "metrics must not be exposed on the public port by default")
+
+	a = NewApp(buildTestContainer(t, &Config{Port: getFreePort(t), MetricsPath: "/metrics"}))
+	a.SetPrepareRouterFn(func(router *gin.Engine, ctn *di.Container) error {
+		router.GET("/metrics", func(c *gin.Context) {
+			c.String(http.StatusOK, "custom")
+		})
+		return nil
+	})
+	if !assert.NotPanics(t, func() { assert.NoError(t, a.Init()) }) {
+		return
+	}
+	w = httptest.NewRecorder()
+	DIGetRouter(a.C()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, "custom", w.Body.String())
+}
+
+func TestApp_Init_Error(t *testing.T) {
+	a := NewApp(buildTestContainer(t, &Config{Port: getFreePort(t), ShutdownTimeout: time.Second}))
+	a.SetPrepareContainerFn(func(ctn *di.Container) error {
+		return errors.New("expected error")
+	})
+	assert.EqualError(t, a.Init(), "expected error")
+	assert.EqualError(t, a.Init(), "expected error", "failed Init must not be reported as succeeded")
+	assert.EqualError(t, a.RunContext(context.Background()), "expected error")
+}
@@ -0,0 +1,273 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/spf13/pflag"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+)
+
+// CLI exit codes
+const (
+	ExitOk    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// cliCommandDft is a command run if no command is given
+const cliCommandDft = "serve"
+
+// AppFactory creates the App after the command-line flags are applied to pagocore.Opt
+type AppFactory func() (*App, error)
+
+// CommandFn is a CLI command function. ctx is cancelled on SIGINT or SIGTERM.
+type CommandFn func(ctx context.Context, ctn *di.Container, args []string) error
+
+// Command is a CLI subcommand
+type Command struct {
+	// Name is a command name to call it by
+	Name string
+
+	// Usage is a short command description
+	Usage string
+
+	// Run runs the command with the rest command-line arguments
+	Run CommandFn
+}
+
+// UsageError is an error of the command arguments, CLI exits with ExitUsage code
+type UsageError struct {
+	Message string
+}
+
+// Error as a string
+func (e *UsageError) Error() string {
+	return e.Message
+}
+
+// NewCLI creates new CLI instance with built-in commands.
+// If factory is nil, App with the default container is created.
+func NewCLI(name string, factory AppFactory) *CLI {
+	if factory == nil {
+		factory = defaultAppFactory
+	}
+	cli := &CLI{
+		Name:    name,
+		Out:     os.Stdout,
+		Err:     os.Stderr,
+		factory: factory,
+	}
+	cli.AddCommands(
+		&Command{Name: "serve", Usage: "run the service until SIGINT or SIGTERM (default)"},
+		&Command{Name: "config", Usage: "print the effective config with secrets masked", Run: cli.printConfig},
+		&Command{Name: "check", Usage: "build all dependencies and run their health checks", Run: cli.check},
+		&Command{Name: "routes", Usage: "print the registered http routes", Run: cli.printRoutes},
+	)
+	return cli
+}
+
+// CLI is a command-line runner of the App:
+//
+//	name [--config path] [command] [args...]
+type CLI struct {
+	// Name is a program name used in the usage
+	Name string
+
+	// Out and Err are the commands output writers, os.Stdout and os.Stderr by default
+	Out io.Writer
+	Err io.Writer
+
+	factory  AppFactory
+	app      *App
+	commands []*Command
+}
+
+// AddCommands registers commands, command with the existing name replaces the registered one
+func (cli *CLI) AddCommands(cmds ...*Command) {
+	for _, cmd := range cmds {
+		if i := cli.commandIndex(cmd.Name); i >= 0 {
+			cli.commands[i] = cmd
+			continue
+		}
+		cli.commands = append(cli.commands, cmd)
+	}
+}
+
+// Execute runs the CLI with os.Args and exits with its code
+func (cli *CLI) Execute() {
+	os.Exit(cli.Run(os.Args[1:]))
+}
+
+// Run parses args, runs the command and returns the exit code
+func (cli *CLI) Run(args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return cli.RunContext(ctx, args)
+}
+
+// RunContext parses args, runs the command until ctx is done and returns the exit code
+func (cli *CLI) RunContext(ctx context.Context, args []string) int {
+	flags := pflag.NewFlagSet(cli.Name, pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.SetOutput(cli.Err)
+	flags.Usage = cli.usage
+	configPath := flags.StringP("config", "c", pagocore.Opt.ConfigFilePath, "config file path")
+
+	err := flags.Parse(args)
+	if errors.Is(err, pflag.ErrHelp) {
+		return ExitOk
+	}
+	if err != nil {
+		return ExitUsage
+	}
+	pagocore.Opt.ConfigFilePath = *configPath
+
+	name, cmdArgs := cliCommandDft, flags.Args()
+	if len(cmdArgs) > 0 {
+		name, cmdArgs = cmdArgs[0], cmdArgs[1:]
+	}
+	if name == "help" {
+		cli.usage()
+		return ExitOk
+	}
+	i := cli.commandIndex(name)
+	if i < 0 {
+		_, _ = fmt.Fprintf(cli.Err, "unknown command %q\n", name)
+		cli.usage()
+		return ExitUsage
+	}
+	cmd := cli.commands[i]
+
+	cli.app, err = cli.factory()
+	if err != nil {
+		_, _ = fmt.Fprintln(cli.Err, "failed to create app:", err)
+		return ExitError
+	}
+
+	if cmd.Run == nil {
+		err = cli.app.RunContext(ctx)
+	} else {
+		// commands need the dependencies added by the container preparation and modules
+		err = cli.app.Init()
+		if err == nil {
+			err = cmd.Run(ctx, cli.app.C(), cmdArgs)
+		}
+		closeErr := cli.app.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}
+
+	usageErr := &UsageError{}
+	switch {
+	case errors.As(err, &usageErr):
+		_, _ = fmt.Fprintf(cli.Err, "%s: %s\n", name, err)
+		return ExitUsage
+	case err != nil:
+		_, _ = fmt.Fprintf(cli.Err, "%s failed: %s\n", name, err)
+		return ExitError
+	}
+	return ExitOk
+}
+
+// App returns the App created for the running command, nil before the command is run
+func (cli *CLI) App() *App {
+	return cli.app
+}
+
+// usage prints the CLI usage
+func (cli *CLI) usage() {
+	_, _ = fmt.Fprintf(cli.Err, "Usage: %s [--config path] [command] [args...]\n\nCommands:\n", cli.Name)
+	w := tabwriter.NewWriter(cli.Err, 0, 4, 2, ' ', 0)
+	for _, cmd := range cli.commands {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", cmd.Name, cmd.Usage)
+	}
+	_ = w.Flush()
+}
+
+// commandIndex returns the index of the command by name, or -1 if not found
+func (cli *CLI) commandIndex(name string) int {
+	for i, cmd := range cli.commands {
+		if cmd.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// printConfig prints the effective config
+func (cli *CLI) printConfig(ctx context.Context, ctn *di.Container, args []string) error {
+	w := tabwriter.NewWriter(cli.Out, 0, 4, 2, ' ', 0)
+	for _, v := range ConfigDump(ctn) {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.Value, v.Source)
+	}
+	return w.Flush()
+}
+
+// check builds all app-scoped dependencies and runs their health checks
+func (cli *CLI) check(ctx context.Context, ctn *di.Container, args []string) error {
+	failed := 0
+	for _, node := range ctn.Graph().Nodes {
+		if node.Scope != di.ScopeApp {
+			continue
+		}
+		if _, err := ctn.SafeGet(node.Name); err != nil {
+			_, _ = fmt.Fprintf(cli.Out, "build\t%s\t%s\n", node.Name, err)
+			failed++
+		}
+	}
+
+	for _, res := range ctn.CheckHealth(ctx) {
+		_, _ = fmt.Fprintf(cli.Out, "%s\t%s\t%s", res.Status, res.Name, res.Latency)
+		if !res.Ok() {
+			_, _ = fmt.Fprintf(cli.Out, "\t%s", res.Error)
+			if res.Critical {
+				failed++
+			}
+		}
+		_, _ = fmt.Fprintln(cli.Out)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d dependencies failed", failed)
+	}
+	return nil
+}
+
+// printRoutes prints the initialized App router routes sorted by path
+func (cli *CLI) printRoutes(ctx context.Context, ctn *di.Container, args []string) error {
+	routes := DIGetRouter(ctn).Routes()
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+
+	w := tabwriter.NewWriter(cli.Out, 0, 4, 2, ' ', 0)
+	for _, r := range routes {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", r.Method, r.Path, r.Handler)
+	}
+	return w.Flush()
+}
+
+// defaultAppFactory creates App with the default container
+func defaultAppFactory() (*App, error) {
+	builder, err := GetDefaultDIBuilder()
+	if err != nil {
+		return nil, err
+	}
+	ctn, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return NewApp(ctn), nil
+}
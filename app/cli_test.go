@@ -0,0 +1,112 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCLI_Run(t *testing.T) {
+	defer func(path string) {
+		pagocore.Opt.ConfigFilePath = path
+	}(pagocore.Opt.ConfigFilePath)
+
+	healthy := true
+	newCLI := func() (*CLI, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		cli := NewCLI("test", func() (*App, error) {
+			a := NewApp(buildTestContainer(t, &Config{Port: getFreePort(t), ShutdownTimeout: time.Second}, di.Def{
+				Name: "test_dep",
+				Lazy: true,
+				Build: func(ctn *di.Container) (interface{}, error) {
+					return "dep", nil
+				},
+				HealthCheck: func(ctx context.Context, obj interface{}) error {
+					if !healthy {
+						return errors.New("expected error")
+					}
+					return nil
+				},
+			}))
+			a.SetPrepareContainerFn(func(ctn *di.Container) error {
+				return ctn.Extend(di.Def{
+					Name: "test_prepared_dep",
+					Build: func(ctn *di.Container) (interface{}, error) {
+						return "prepared", nil
+					},
+				})
+			})
+			a.SetPrepareRouterFn(func(router *gin.Engine, ctn *di.Container) error {
+				router.GET("/ping", func(c *gin.Context) {
+					c.String(http.StatusOK, "pong")
+				})
+				return nil
+			})
+			return a, nil
+		})
+		cli.Out, cli.Err = out, out
+		return cli, out
+	}
+
+	cli, out := newCLI()
+	assert.Equal(t, ExitUsage, cli.Run([]string{"unknown"}))
+	assert.Contains(t, out.String(), "routes")
+
+	cli, _ = newCLI()
+	assert.Equal(t, ExitUsage, cli.Run([]string{"--unknown"}))
+
+	cli, _ = newCLI()
+	assert.Equal(t, ExitOk, cli.Run([]string{"help"}))
+
+	cli, out = newCLI()
+	assert.Equal(t, ExitOk, cli.Run([]string{"-c", "custom.env", "routes"}))
+	assert.Equal(t, "custom.env", pagocore.Opt.ConfigFilePath)
+	assert.Regexp(t, `GET\s+/ping`, out.String())
+	assert.Regexp(t, `GET\s+\S+/healthz`, out.String())
+
+	cli, out = newCLI()
+	assert.Equal(t, ExitOk, cli.Run([]string{"config"}))
+	assert.Contains(t, out.String(), "service_port")
+
+	cli, out = newCLI()
+	assert.Equal(t, ExitOk, cli.Run([]string{"check"}))
+	assert.Regexp(t, `ok\s+test_dep`, out.String())
+
+	healthy = false
+	cli, out = newCLI()
+	assert.Equal(t, ExitError, cli.Run([]string{"check"}))
+	assert.Contains(t, out.String(), "expected error")
+
+	var gotArgs []string
+	var gotCtn *di.Container
+	cli, _ = newCLI()
+	cli.AddCommands(&Command{
+		Name: "migrate",
+		Run: func(ctx context.Context, ctn *di.Container, args []string) error {
+			gotCtn, gotArgs = ctn, args
+			if _, err := ctn.SafeGet("test_prepared_dep"); err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return &UsageError{Message: "version is required"}
+			}
+			return nil
+		},
+	})
+	assert.Equal(t, ExitOk, cli.Run([]string{"migrate", "--to", "v2"}))
+	assert.Equal(t, []string{"--to", "v2"}, gotArgs)
+	assert.Same(t, cli.App().C(), gotCtn)
+	assert.Equal(t, ExitUsage, cli.Run([]string{"migrate"}))
+
+	cli, _ = newCLI()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, ExitOk, cli.RunContext(ctx, nil))
+}
@@ -5,63 +5,130 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"os"
+	"time"
 )
 
 // i18nFileDft is a default i18n file path
 const i18nFileDft = "./i18n.yml"
 
-// Config is a basic service config
+// shutdownTimeoutDft is a default time to wait for in-flight requests on shutdown
+const shutdownTimeoutDft = 10 * time.Second
+
+// Config is a basic service config.
+// Fields are loaded by LoadConfig according to their tags.
 type Config struct {
-	Port     string
-	LogLevel log.Level
+	Port     string    `key:"service_port" required:"true"`
+	LogLevel log.Level `key:"log_level"`
 
-	RedisHost     string
-	RedisDb       int
-	RedisPassword string
+	// ShutdownTimeout is a time to wait for in-flight requests on shutdown
+	ShutdownTimeout time.Duration `key:"shutdown_timeout" default:"10s" min:"1s"`
 
-	MongoHost     string
-	MongoUser     string
-	MongoPassword string
-	MongoDatabase string
+	// TLSCertFile and TLSKeyFile enable TLS, the files are reloaded when they are changed
+	TLSCertFile string `key:"tls_cert_file"`
+	TLSKeyFile  string `key:"tls_key_file"`
+	// TLSClientCAFile enables client certificates verification for mTLS
+	TLSClientCAFile string `key:"tls_client_ca_file"`
+	// TLSMinVersion is a minimal TLS version: 1.0, 1.1, 1.2 or 1.3
+	TLSMinVersion string `key:"tls_min_version" default:"1.2"`
+	// TLSCipherSuites is a list of allowed TLS 1.0-1.2 cipher suites names, Go defaults if empty
+	TLSCipherSuites []string `key:"tls_cipher_suites"`
+	// H2C enables HTTP/2 over plaintext connections if TLS is disabled
+	H2C bool `key:"h2c"`
 
-	JWTPassword []byte
+	// ReadHeaderTimeout is a time to read the request headers
+	ReadHeaderTimeout time.Duration `key:"http_read_header_timeout" default:"10s" min:"0s"`
+	// ReadTimeout is a time to read the entire request including the body
+	ReadTimeout time.Duration `key:"http_read_timeout" default:"30s" min:"0s"`
+	// WriteTimeout is a time from the end of the request headers read to the end of the response write
+	WriteTimeout time.Duration `key:"http_write_timeout" default:"60s" min:"0s"`
+	// IdleTimeout is a time to wait for the next request on keep-alive connections
+	IdleTimeout time.Duration `key:"http_idle_timeout" default:"120s" min:"0s"`
+	// MaxHeaderBytes limits the request headers size
+	MaxHeaderBytes int `key:"http_max_header_bytes" default:"1048576" min:"1024"`
+	// MaxConnections limits the number of concurrent connections, unlimited if zero
+	MaxConnections int `key:"http_max_connections" default:"0" min:"0"`
 
-	I18nFile string
-}
+	// MetricsPath is a path of Prometheus metrics endpoint on the public port, disabled by default.
+	// Metrics are always exposed on the admin server. The route is skipped if the service defines it.
+	MetricsPath string `key:"metrics_path"`
 
-// SetFromViper applies values from the viper config to the Config instance
-func (c *Config) SetFromViper(conf *viper.Viper) {
-	logLvl, err := log.ParseLevel(conf.GetString("log_level"))
-	if err != nil {
-		logLvl = pagocore.Opt.LogLevelDft
-	}
+	// TracingExporter enables tracing with "stdout" or "otlp" span exporter
+	TracingExporter string `key:"tracing_exporter"`
+	// TracingOTLPEndpoint is the OTLP/HTTP collector host:port, OTEL_EXPORTER_OTLP_* environment variables are used if empty
+	TracingOTLPEndpoint string `key:"tracing_otlp_endpoint"`
+	// TracingOTLPInsecure disables TLS for the OTLP exporter
+	TracingOTLPInsecure bool `key:"tracing_otlp_insecure"`
+	// TracingSampleRatio is a ratio of sampled root traces, 0 to 1
+	TracingSampleRatio float64 `key:"tracing_sample_ratio" default:"1" min:"0" max:"1"`
 
-	c.Port = conf.GetString("service_port")
-	c.LogLevel = logLvl
+	// AdminPort enables the admin server with diagnostic endpoints, see NewAdminRouter
+	AdminPort string `key:"admin_port"`
+	// AdminHost is the admin server bind host, keep it private
+	AdminHost string `key:"admin_host" default:"127.0.0.1"`
 
-	c.RedisHost = conf.GetString("redis_host")
-	c.RedisDb = conf.GetInt("redis_db")
-	c.RedisPassword = conf.GetString("redis_password")
+	RedisHost     string `key:"redis_host"`
+	RedisDb       int    `key:"redis_db" min:"0" max:"15"`
+	RedisPassword string `key:"redis_password" secret:"true"`
 
-	c.MongoHost = conf.GetString("mongo_host")
-	c.MongoUser = conf.GetString("mongo_username")
-	c.MongoPassword = conf.GetString("mongo_password")
-	c.MongoDatabase = conf.GetString("mongo_db")
+	MongoHost     string `key:"mongo_host"`
+	MongoUser     string `key:"mongo_username"`
+	MongoPassword string `key:"mongo_password" secret:"true"`
+	MongoDatabase string `key:"mongo_db"`
 
-	c.JWTPassword = []byte(conf.GetString("jwt_password"))
+	JWTPassword []byte `key:"jwt_password" required:"true" secret:"true"`
 
-	c.I18nFile = conf.GetString("i18n_file")
-	if c.I18nFile == "" {
-		if _, err := os.Stat(i18nFileDft); err == nil {
-			c.I18nFile = i18nFileDft
-		}
+	I18nFile string `key:"i18n_file"`
+
+	// DisabledModules is a list of names of the App modules not to mount
+	DisabledModules []string `key:"modules_disabled"`
+}
+
+// GetConfig returns the basic config, makes Config embeddable to the ServiceConfig
+func (c *Config) GetConfig() *Config {
+	return c
+}
+
+// String returns the Config representation with secrets masked
+func (c *Config) String() string {
+	return configString(c)
+}
+
+// IsTLSEnabled checks if the server should serve TLS
+func (c *Config) IsTLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != ""
+}
+
+// SetDefaults sets default values which are not set by the config tags
+func (c *Config) SetDefaults() {
+	c.LogLevel = pagocore.Opt.LogLevelDft
+	c.ShutdownTimeout = shutdownTimeoutDft
+	if _, err := os.Stat(i18nFileDft); err == nil {
+		c.I18nFile = i18nFileDft
 	}
+}
 
+// SetFromViper applies values from the viper config to the Config instance.
+// Invalid values are ignored, use LoadConfig to validate the config.
+func (c *Config) SetFromViper(conf *viper.Viper) {
+	err := LoadConfig(conf, c)
+	if err != nil {
+		log.Warn(logTag, err)
+	}
 	c.ApplyToGlobals()
 }
 
+// IsModuleEnabled checks if the App module is not disabled
+func (c *Config) IsModuleEnabled(name string) bool {
+	for _, disabled := range c.DisabledModules {
+		if disabled == name {
+			return false
+		}
+	}
+	return true
+}
+
 // ApplyToGlobals applies values from the Config instance to global instances
 func (c *Config) ApplyToGlobals() {
 	log.SetLevel(c.LogLevel)
-	pagocore.Opt.JWTPassword = c.JWTPassword
+	pagocore.Opt.SetJWTPassword(c.JWTPassword)
 }
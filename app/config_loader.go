@@ -0,0 +1,348 @@
+package app
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config struct tags
+const (
+	// tagKey is a config key name, e. g. `key:"service_port"`
+	tagKey = "key"
+	// tagDefault is a default value if the key is not set
+	tagDefault = "default"
+	// tagRequired marks the key as required, e. g. `required:"true"`
+	tagRequired = "required"
+	// tagMin is a minimal numeric value or string length
+	tagMin = "min"
+	// tagMax is a maximal numeric value or string length
+	tagMax = "max"
+	// tagSecret marks the value as secret to never print it
+	tagSecret = "secret"
+)
+
+// ServiceConfig is a service-specific config struct embedding Config
+type ServiceConfig interface {
+	GetConfig() *Config
+}
+
+// configDefaulter is a config with default values not expressible by tags
+type configDefaulter interface {
+	SetDefaults()
+}
+
+// ConfigFieldError is an error of the config key
+type ConfigFieldError struct {
+	Key     string
+	Message string
+}
+
+// Error as a string
+func (e *ConfigFieldError) Error() string {
+	return e.Key + ": " + e.Message
+}
+
+// ConfigError lists all invalid or missing config keys
+type ConfigError struct {
+	Fields []*ConfigFieldError
+}
+
+// Error as a string
+func (e *ConfigError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+// configField is a tagged config struct field
+type configField struct {
+	key      string
+	dft      string
+	hasDft   bool
+	required bool
+	min      string
+	max      string
+	secret   bool
+	value    reflect.Value
+}
+
+// LoadConfig fills the target struct pointer from the viper config according to the fields tags.
+// Secret fields values may be read from the file which path is set by the key with "_file" suffix.
+// Use pagocore.ReadConfig to get viper with environment variables and flags applied.
+// If target implements SetDefaults(), it is called first.
+// Returns ConfigError with all invalid or missing keys, valid keys are applied anyway.
+func LoadConfig(conf *viper.Viper, target interface{}) error {
+	if d, ok := target.(configDefaulter); ok {
+		d.SetDefaults()
+	}
+
+	fields, err := configFields(target)
+	if err != nil {
+		return err
+	}
+
+	confErr := &ConfigError{}
+	for _, f := range fields {
+		raw, ok, err := f.lookup(conf)
+		if err != nil {
+			confErr.Fields = append(confErr.Fields, &ConfigFieldError{Key: f.key, Message: err.Error()})
+			continue
+		}
+		if !ok && f.hasDft {
+			raw, ok = f.dft, true
+		}
+		if !ok {
+			if f.required {
+				confErr.Fields = append(confErr.Fields, &ConfigFieldError{Key: f.key, Message: "is required"})
+			}
+			continue
+		}
+		err = f.set(raw)
+		if err != nil {
+			confErr.Fields = append(confErr.Fields, &ConfigFieldError{Key: f.key, Message: err.Error()})
+		}
+	}
+
+	if len(confErr.Fields) > 0 {
+		return confErr
+	}
+	return nil
+}
+
+// lookup returns non-empty raw value of the field, reading secret file if its path is set
+func (f *configField) lookup(conf *viper.Viper) (string, bool, error) {
+	if f.secret {
+		path, ok := lookupConfigValue(conf, f.key+secretFileSuffix)
+		if ok {
+			raw, err := readSecretFile(path)
+			return raw, raw != "", err
+		}
+	}
+	raw, ok := lookupConfigValue(conf, f.key)
+	return raw, ok, nil
+}
+
+// lookupConfigValue returns non-empty value of the key from viper
+func lookupConfigValue(conf *viper.Viper, key string) (string, bool) {
+	if conf == nil || !conf.IsSet(key) {
+		return "", false
+	}
+
+	var v string
+	switch val := conf.Get(key).(type) {
+	case []interface{}:
+		v = strings.Join(cast.ToStringSlice(val), ",")
+	case []string:
+		v = strings.Join(val, ",")
+	default:
+		v = cast.ToString(val)
+	}
+	return v, v != ""
+}
+
+// configFields returns tagged fields of the struct pointer, including embedded and nested structs
+func configFields(target interface{}) ([]*configField, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%sconfig target must be a pointer to struct, %T given", logTag, target)
+	}
+	return structConfigFields(v.Elem()), nil
+}
+
+// structConfigFields returns tagged fields of the struct value
+func structConfigFields(v reflect.Value) []*configField {
+	var fields []*configField
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		key, ok := sf.Tag.Lookup(tagKey)
+		if !ok {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Time{}) {
+				fields = append(fields, structConfigFields(fv)...)
+			}
+			continue
+		}
+
+		f := &configField{
+			key:   key,
+			min:   sf.Tag.Get(tagMin),
+			max:   sf.Tag.Get(tagMax),
+			value: v.Field(i),
+		}
+		f.dft, f.hasDft = sf.Tag.Lookup(tagDefault)
+		f.required, _ = strconv.ParseBool(sf.Tag.Get(tagRequired))
+		f.secret, _ = strconv.ParseBool(sf.Tag.Get(tagSecret))
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	logLevelType = reflect.TypeOf(log.Level(0))
+)
+
+// set parses the raw value and sets it to the field
+func (f *configField) set(raw string) error {
+	v := f.value
+	invalid := func(kind string) error {
+		if f.secret {
+			return fmt.Errorf("not a valid %s", kind)
+		}
+		return fmt.Errorf("not a valid %s: %q", kind, raw)
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return invalid("duration")
+		}
+		if err := f.checkRange(float64(d), parseDurationFloat); err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Type() == logLevelType:
+		lvl, err := log.ParseLevel(raw)
+		if err != nil {
+			return invalid("log level")
+		}
+		v.SetUint(uint64(lvl))
+	case v.Kind() == reflect.String:
+		if err := f.checkRange(float64(len(raw)), parseFloat); err != nil {
+			return err
+		}
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalid("boolean")
+		}
+		v.SetBool(b)
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return invalid("integer")
+		}
+		if err := f.checkRange(float64(n), parseFloat); err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return invalid("unsigned integer")
+		}
+		if err := f.checkRange(float64(n), parseFloat); err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return invalid("number")
+		}
+		if err := f.checkRange(n, parseFloat); err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		if err := f.checkRange(float64(len(raw)), parseFloat); err != nil {
+			return err
+		}
+		v.SetBytes([]byte(raw))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// checkRange validates the value against min and max tags
+func (f *configField) checkRange(n float64, parse func(string) (float64, error)) error {
+	if f.min != "" {
+		min, err := parse(f.min)
+		if err != nil {
+			return fmt.Errorf("invalid min tag: %q", f.min)
+		}
+		if n < min {
+			return fmt.Errorf("must be at least %s", f.min)
+		}
+	}
+	if f.max != "" {
+		max, err := parse(f.max)
+		if err != nil {
+			return fmt.Errorf("invalid max tag: %q", f.max)
+		}
+		if n > max {
+			return fmt.Errorf("must be at most %s", f.max)
+		}
+	}
+	return nil
+}
+
+// parseFloat parses numeric tag value
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseDurationFloat parses duration tag value
+func parseDurationFloat(s string) (float64, error) {
+	d, err := time.ParseDuration(s)
+	return float64(d), err
+}
+
+// ConfigKeys returns keys of the target struct pointer fields
+func ConfigKeys(target interface{}) []string {
+	fields, err := configFields(target)
+	if err != nil {
+		return nil
+	}
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.key
+	}
+	return keys
+}
+
+// ConfigSecretKeys returns keys of the target struct pointer fields tagged as secret
+func ConfigSecretKeys(target interface{}) []string {
+	fields, err := configFields(target)
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for _, f := range fields {
+		if f.secret {
+			keys = append(keys, f.key)
+		}
+	}
+	return keys
+}
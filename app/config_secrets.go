@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/proactiongo/pagocore/di"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// secretFileSuffix is a suffix of the key containing path to the secret file, e. g. jwt_password_file
+const secretFileSuffix = "_file"
+
+// secretMask replaces secret values in the config string representation
+const secretMask = "******"
+
+// secretsWatchIntervalDft is a default interval of secret files polling
+const secretsWatchIntervalDft = 30 * time.Second
+
+// readSecretFile reads secret value from the file trimming surrounding whitespace
+func readSecretFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// ReloadSecretFiles re-reads secret fields of the target struct pointer from their files
+// and updates changed values in place, so the target must not be in use. Returns keys of the changed fields.
+func ReloadSecretFiles(conf *viper.Viper, target interface{}) ([]string, error) {
+	fields, err := configFields(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	confErr := &ConfigError{}
+	for _, f := range fields {
+		if !f.secret {
+			continue
+		}
+		path, ok := lookupConfigValue(conf, f.key+secretFileSuffix)
+		if !ok {
+			continue
+		}
+		raw, err := readSecretFile(path)
+		if err == nil && raw == f.string() {
+			continue
+		}
+		if err == nil {
+			err = f.set(raw)
+		}
+		if err != nil {
+			confErr.Fields = append(confErr.Fields, &ConfigFieldError{Key: f.key, Message: err.Error()})
+			continue
+		}
+		changed = append(changed, f.key)
+	}
+
+	if len(confErr.Fields) > 0 {
+		return changed, confErr
+	}
+	return changed, nil
+}
+
+// WatchSecretFiles registers the App hook polling secret files with the interval
+// (secretsWatchIntervalDft if zero). Changed values are loaded to the copy of DIGetServiceConfig(),
+// which replaces the config in the container, and are applied to the globals.
+// Get the config from the container on each use to see the changes.
+func (a *App) WatchSecretFiles(interval time.Duration) {
+	if interval <= 0 {
+		interval = secretsWatchIntervalDft
+	}
+
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	a.AddHooks(Hook{
+		Name: "secret files watcher",
+		OnStart: func(_ context.Context, ctn *di.Container) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						a.reloadSecretFiles()
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context, ctn *di.Container) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+// reloadSecretFiles reloads secrets of the config registered in the container to its copy
+// and replaces the config if any secret is changed
+func (a *App) reloadSecretFiles() {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	ctn := a.C()
+	next := copyServiceConfig(DIGetServiceConfig(ctn))
+
+	changed, err := ReloadSecretFiles(DIGetConfigViper(ctn), next)
+	if err != nil {
+		log.Error(logTag, "failed to reload secret files: ", err)
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	err = replaceServiceConfig(ctn, next)
+	if err != nil {
+		log.Error(logTag, "failed to apply reloaded secrets: ", err)
+		return
+	}
+	next.GetConfig().ApplyToGlobals()
+	log.Info(logTag, "secrets reloaded: ", strings.Join(changed, ", "))
+}
+
+// replaceServiceConfig replaces DIServiceConfig and DIConfig in the container with next.
+// The previous config is not modified, so concurrent readers keep using it safely.
+func replaceServiceConfig(ctn *di.Container, next ServiceConfig) error {
+	if ctn.Has(DIServiceConfig) {
+		err := ctn.Replace(DIServiceConfig, next)
+		if err != nil {
+			return err
+		}
+	}
+	return ctn.Replace(DIConfig, next.GetConfig())
+}
+
+// copyServiceConfig returns a copy of the config struct pointer including nested config structs
+func copyServiceConfig(conf ServiceConfig) ServiceConfig {
+	v := reflect.ValueOf(conf)
+	cp := reflect.New(v.Type().Elem())
+	copyConfigStruct(cp.Elem(), v.Elem())
+	return cp.Interface().(ServiceConfig)
+}
+
+// copyConfigStruct copies the src struct value to dst, pointers to nested structs are copied too
+func copyConfigStruct(dst, src reflect.Value) {
+	dst.Set(src)
+	for i := 0; i < src.NumField(); i++ {
+		fv := src.Field(i)
+		if !dst.Field(i).CanSet() {
+			continue
+		}
+		switch {
+		case fv.Kind() == reflect.Ptr && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct:
+			cp := reflect.New(fv.Type().Elem())
+			copyConfigStruct(cp.Elem(), fv.Elem())
+			dst.Field(i).Set(cp)
+		case fv.Kind() == reflect.Struct:
+			copyConfigStruct(dst.Field(i), fv)
+		}
+	}
+}
+
+// string returns current field value as a string
+func (f *configField) string() string {
+	v := f.value
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return string(v.Bytes())
+	}
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// configString returns the string representation of the config struct pointer with secrets masked
+func configString(target interface{}) string {
+	fields, err := configFields(target)
+	if err != nil {
+		return fmt.Sprint(err)
+	}
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		val := f.string()
+		if f.secret && val != "" {
+			val = secretMask
+		}
+		values[i] = f.key + "=" + val
+	}
+	return "{" + strings.Join(values, " ") + "}"
+}
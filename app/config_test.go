@@ -2,9 +2,16 @@ package app
 
 import (
 	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestConfig_SetFromViper(t *testing.T) {
@@ -27,3 +34,164 @@ func TestConfig_SetFromViper(t *testing.T) {
 	assert.Equal(t, []byte("12345"), pagocore.Opt.JWTPassword)
 	assert.Equal(t, log.InfoLevel, log.GetLevel())
 }
+
+type testServiceConfig struct {
+	Config
+
+	Workers  int           `key:"workers" default:"4" min:"1" max:"32"`
+	Interval time.Duration `key:"interval" required:"true"`
+	Tags     []string      `key:"tags"`
+	APIKey   string        `key:"api_key" secret:"true" min:"8"`
+}
+
+func TestLoadConfig(t *testing.T) {
+	v := viper.New()
+	v.Set("service_port", "8080")
+	v.Set("jwt_password", "secret")
+	v.Set("interval", "1m")
+	v.Set("tags", "a, b,,c")
+
+	conf := &testServiceConfig{}
+	if !assert.NoError(t, LoadConfig(v, conf)) {
+		return
+	}
+	assert.Equal(t, "8080", conf.Port)
+	assert.Equal(t, pagocore.Opt.LogLevelDft, conf.LogLevel)
+	assert.Equal(t, shutdownTimeoutDft, conf.ShutdownTimeout)
+	assert.Equal(t, 10*time.Second, conf.ReadHeaderTimeout)
+	assert.Equal(t, 1<<20, conf.MaxHeaderBytes)
+	assert.Zero(t, conf.MaxConnections)
+	assert.Empty(t, conf.MetricsPath, "metrics must be served only by the admin server by default")
+	assert.Equal(t, 4, conf.Workers)
+	assert.Equal(t, time.Minute, conf.Interval)
+	assert.Equal(t, []string{"a", "b", "c"}, conf.Tags)
+	assert.Same(t, &conf.Config, conf.GetConfig())
+
+	t.Setenv("WORKERS", "8")
+	v.AutomaticEnv()
+	assert.NoError(t, LoadConfig(v, conf))
+	assert.Equal(t, 8, conf.Workers)
+	t.Setenv("WORKERS", "")
+
+	v = viper.New()
+	v.Set("redis_db", "first")
+	v.Set("workers", "100")
+	v.Set("api_key", "short")
+	v.Set("log_level", "loud")
+
+	err := LoadConfig(v, &testServiceConfig{})
+	confErr := &ConfigError{}
+	if !assert.ErrorAs(t, err, &confErr) {
+		return
+	}
+	keys := make([]string, len(confErr.Fields))
+	for i, f := range confErr.Fields {
+		keys[i] = f.Key
+	}
+	assert.ElementsMatch(t, []string{"service_port", "log_level", "redis_db", "jwt_password", "workers", "interval", "api_key"}, keys)
+	assert.NotContains(t, err.Error(), "short")
+
+	assert.Error(t, LoadConfig(v, conf.Config))
+}
+
+func TestLoadConfig_SecretFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt_password")
+	assert.NoError(t, os.WriteFile(path, []byte("from_file\n"), 0600))
+
+	v := viper.New()
+	v.Set("service_port", "8080")
+	v.Set("jwt_password", "plain")
+	v.Set("jwt_password_file", path)
+	v.Set("mongo_password", "mongo_secret")
+
+	conf := &Config{}
+	if !assert.NoError(t, LoadConfig(v, conf)) {
+		return
+	}
+	assert.Equal(t, []byte("from_file"), conf.JWTPassword)
+	assert.NotContains(t, conf.String(), "from_file")
+	assert.NotContains(t, conf.String(), "mongo_secret")
+	assert.Contains(t, conf.String(), "service_port=8080")
+	assert.ElementsMatch(t, []string{"jwt_password", "mongo_password", "redis_password"}, ConfigSecretKeys(conf))
+
+	changed, err := ReloadSecretFiles(v, conf)
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+
+	assert.NoError(t, os.WriteFile(path, []byte("rotated"), 0600))
+	changed, err = ReloadSecretFiles(v, conf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"jwt_password"}, changed)
+	assert.Equal(t, []byte("rotated"), conf.JWTPassword)
+
+	assert.NoError(t, os.Remove(path))
+	_, err = ReloadSecretFiles(v, conf)
+	assert.Error(t, err)
+	assert.Error(t, LoadConfig(v, &Config{}))
+}
+
+func TestApp_reloadSecretFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_key")
+	assert.NoError(t, os.WriteFile(path, []byte("initial_key"), 0600))
+
+	v := viper.New()
+	v.Set("service_port", "8080")
+	v.Set("jwt_password_file", path)
+	v.Set("api_key_file", path)
+	v.Set("interval", "1m")
+
+	initial := &testServiceConfig{}
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: DIConfigViper,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return v, nil
+			},
+		},
+		DIDefServiceConfig(initial),
+		DIDefConfig(),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	a := NewApp(ctn)
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				conf := di.Get[ServiceConfig](ctn, DIServiceConfig).(*testServiceConfig)
+				_ = conf.APIKey + string(conf.JWTPassword)
+				_ = string(DIGetConfig(ctn).JWTPassword)
+				_ = string(pagocore.Opt.GetJWTPassword())
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, os.WriteFile(path, []byte("rotated_key_"+strconv.Itoa(i)), 0600))
+		a.reloadSecretFiles()
+	}
+	close(stop)
+	wg.Wait()
+
+	conf := DIGetServiceConfig(ctn).(*testServiceConfig)
+	assert.Equal(t, "rotated_key_19", conf.APIKey)
+	assert.Equal(t, []byte("rotated_key_19"), DIGetConfig(ctn).JWTPassword)
+	assert.Same(t, conf.GetConfig(), DIGetConfig(ctn))
+	assert.Equal(t, []byte("rotated_key_19"), pagocore.Opt.GetJWTPassword())
+	assert.Equal(t, "initial_key", initial.APIKey, "published config must not be modified")
+}
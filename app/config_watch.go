@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/proactiongo/pagocore/i18n"
+	"github.com/proactiongo/pagocore/utils"
+	log "github.com/sirupsen/logrus"
+	"reflect"
+)
+
+// ConfigSubscriber is notified with previous and next config values after the config reload
+type ConfigSubscriber func(prev, next ServiceConfig)
+
+// ConfigSubscriberGlobals applies the next config to the globals, e. g. log level
+func ConfigSubscriberGlobals(prev, next ServiceConfig) {
+	next.GetConfig().ApplyToGlobals()
+}
+
+// ConfigSubscriberI18n reloads i18n.Source from the next config's i18n file and replaces it with i18n.SetSource
+func ConfigSubscriberI18n(prev, next ServiceConfig) {
+	path := next.GetConfig().I18nFile
+	if path == "" {
+		return
+	}
+	src, err := i18n.NewSourceFromFile(path)
+	if err != nil {
+		log.Error(logTag, "failed to reload i18n file: ", err)
+		return
+	}
+	i18n.SetSource(src)
+}
+
+// SubscribeConfig registers config change subscribers, called in order of registration
+func (a *App) SubscribeConfig(fns ...ConfigSubscriber) {
+	a.configSubscribers = append(a.configSubscribers, fns...)
+}
+
+// WatchConfig registers the App hook reloading config when its files or the i18n file change.
+// ConfigSubscriberGlobals and ConfigSubscriberI18n are notified first.
+func (a *App) WatchConfig() {
+	var stop func()
+	a.AddHooks(Hook{
+		Name: "config watcher",
+		OnStart: func(ctx context.Context, ctn *di.Container) error {
+			if !ctn.Has(DIConfigLoader) {
+				return errors.New(logTag + "config loader is not registered")
+			}
+			paths := append([]string{}, DIGetConfigLoader(ctn).Files...)
+			paths = append(paths, DIGetConfig(ctn).I18nFile)
+
+			var err error
+			stop, err = utils.WatchFiles(paths, func() {
+				_ = a.ReloadConfig()
+			})
+			return err
+		},
+		OnStop: func(ctx context.Context, ctn *di.Container) error {
+			stop()
+			return nil
+		},
+	})
+}
+
+// ReloadConfig reads config from DIConfigLoader and validates it.
+// Valid config replaces DIConfigViper, DIServiceConfig and DIConfig in the container, then subscribers are notified.
+// Tagged fields are loaded from scratch, untagged fields set by the service are copied from the previous config.
+// The previous config is not modified, so get the config from the container on each use to see the changes.
+func (a *App) ReloadConfig() error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	ctn := a.C()
+	if !ctn.Has(DIConfigLoader) {
+		return errors.New(logTag + "config loader is not registered")
+	}
+
+	conf, err := DIGetConfigLoader(ctn).Load()
+	if err != nil {
+		log.Error(logTag, "failed to reload config: ", err)
+		return err
+	}
+
+	prev := DIGetServiceConfig(ctn)
+	next := copyServiceConfig(prev)
+	err = resetConfigFields(next)
+	if err != nil {
+		return err
+	}
+	err = LoadConfig(conf, next)
+	if err != nil {
+		log.Error(logTag, "invalid config is not applied: ", err)
+		return err
+	}
+
+	if ctn.Has(DIConfigViper) {
+		err = ctn.Replace(DIConfigViper, conf)
+		if err != nil {
+			return err
+		}
+	}
+	err = replaceServiceConfig(ctn, next)
+	if err != nil {
+		log.Error(logTag, "failed to apply reloaded config: ", err)
+		return err
+	}
+
+	log.Info(logTag, "config reloaded")
+	prevTexts := i18n.GetSource()
+	subscribers := append([]ConfigSubscriber{ConfigSubscriberGlobals, ConfigSubscriberI18n}, a.configSubscribers...)
+	for _, fn := range subscribers {
+		fn(prev, next)
+	}
+
+	// the container texts source follows the global one if it is replaced by ConfigSubscriberI18n
+	texts := i18n.GetSource()
+	if texts != prevTexts && ctn.Has(DII18n) && DIGetI18n(ctn) == prevTexts {
+		return ctn.Replace(DII18n, texts)
+	}
+	return nil
+}
+
+// resetConfigFields sets tagged fields of the target struct pointer to zero values
+func resetConfigFields(target interface{}) error {
+	fields, err := configFields(target)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		f.value.Set(reflect.Zero(f.value.Type()))
+	}
+	return nil
+}
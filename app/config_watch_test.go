@@ -0,0 +1,185 @@
+package app
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/proactiongo/pagocore/ginsrv"
+	"github.com/proactiongo/pagocore/i18n"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestApp_ReloadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.env")
+	assert.NoError(t, os.WriteFile(path, []byte("SERVICE_PORT=8080\nJWT_PASSWORD=secret\nLOG_LEVEL=info\n"), 0644))
+
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: DIConfigLoader,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return &pagocore.ConfigLoader{Files: []string{path}}, nil
+			},
+		},
+		DIDefConfigViper(),
+		DIDefConfig(),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	a := NewApp(ctn)
+	initial := DIGetConfig(ctn)
+	var prevLevel, nextLevel log.Level
+	a.SubscribeConfig(func(prev, next ServiceConfig) {
+		prevLevel = prev.GetConfig().LogLevel
+		nextLevel = next.GetConfig().LogLevel
+	})
+
+	assert.NoError(t, os.WriteFile(path, []byte("SERVICE_PORT=8080\nJWT_PASSWORD=secret\nLOG_LEVEL=debug\n"), 0644))
+	assert.NoError(t, a.ReloadConfig())
+	assert.Equal(t, log.InfoLevel, prevLevel)
+	assert.Equal(t, log.DebugLevel, nextLevel)
+	assert.Equal(t, log.DebugLevel, DIGetConfig(ctn).LogLevel)
+	assert.Equal(t, log.DebugLevel, log.GetLevel())
+	assert.Equal(t, log.InfoLevel, initial.LogLevel, "published config must not be modified")
+	assert.Equal(t, "debug", DIGetConfigViper(ctn).GetString("log_level"))
+
+	assert.NoError(t, os.WriteFile(path, []byte("JWT_PASSWORD=secret\nLOG_LEVEL=warn\n"), 0644))
+	assert.Error(t, a.ReloadConfig())
+	assert.Equal(t, log.DebugLevel, DIGetConfig(ctn).LogLevel)
+	assert.Equal(t, "8080", DIGetConfig(ctn).Port)
+
+	log.SetLevel(log.InfoLevel)
+}
+
+func TestApp_ReloadConfig_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.env")
+	i18nPath := filepath.Join(dir, "i18n.yml")
+	writeConfig := func(i int) {
+		n := strconv.Itoa(i)
+		assert.NoError(t, os.WriteFile(path, []byte("SERVICE_PORT="+n+"\nJWT_PASSWORD=secret\nI18N_FILE="+i18nPath+"\n"), 0644))
+		assert.NoError(t, os.WriteFile(i18nPath, []byte("default_lang: en\ntranslations:\n  hello:\n    ru:\n      text: privet"+n+"\n"), 0644))
+	}
+	writeConfig(0)
+
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: DIConfigLoader,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return &pagocore.ConfigLoader{Files: []string{path}}, nil
+			},
+		},
+		DIDefConfigViper(),
+		DIDefConfig(),
+		DIDefI18n(),
+		DIDefRouter(),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer i18n.SetSource(&i18n.TextsSource{DefaultLang: i18n.LangEn, Translations: make(i18n.Translations)})
+
+	a := NewApp(ctn)
+	if !assert.NoError(t, a.Init()) {
+		return
+	}
+	router := DIGetRouter(ctn)
+	router.GET("/conf", func(c *gin.Context) {
+		ctx := ginsrv.NewContextHandler(c)
+		conf := DIGetConfig(ctx.GetContainer())
+		c.String(http.StatusOK, conf.Port+" "+ctx.GetI18nSource().T("hello", i18n.LangRu, nil)+" "+i18n.T("hello", i18n.LangRu, nil))
+	})
+
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/conf", nil))
+				assert.Equal(t, http.StatusOK, w.Code)
+			}
+		}()
+	}
+
+	for i := 1; i <= 20; i++ {
+		writeConfig(i)
+		assert.NoError(t, a.ReloadConfig())
+	}
+	close(stop)
+	wg.Wait()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/conf", nil))
+	assert.Equal(t, "20 privet20 privet20", w.Body.String())
+}
+
+type testReloadConfig struct {
+	Config
+
+	Workers int `key:"workers"`
+
+	// Name is set by the service, not loaded
+	Name string
+}
+
+func TestApp_ReloadConfig_ServiceConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.env")
+	assert.NoError(t, os.WriteFile(path, []byte("SERVICE_PORT=8080\nJWT_PASSWORD=secret\nWORKERS=4\n"), 0644))
+
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: DIConfigLoader,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return &pagocore.ConfigLoader{Files: []string{path}}, nil
+			},
+		},
+		DIDefConfigViper(),
+		DIDefServiceConfig(&testReloadConfig{Name: "service"}),
+		DIDefConfig(),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	a := NewApp(ctn)
+	assert.NoError(t, os.WriteFile(path, []byte("SERVICE_PORT=8081\nJWT_PASSWORD=secret\n"), 0644))
+	assert.NoError(t, a.ReloadConfig())
+
+	conf := DIGetServiceConfig(ctn).(*testReloadConfig)
+	assert.Equal(t, "8081", conf.Port)
+	assert.Equal(t, "service", conf.Name, "untagged fields must be kept")
+	assert.Zero(t, conf.Workers, "removed keys must not keep the previous values")
+	assert.Same(t, conf.GetConfig(), DIGetConfig(ctn))
+}
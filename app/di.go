@@ -9,19 +9,27 @@ import (
 	"github.com/proactiongo/pagocore/ginsrv"
 	"github.com/proactiongo/pagocore/i18n"
 	"github.com/proactiongo/pagocore/mongodb"
+	"github.com/proactiongo/pagocore/tracing"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"time"
 )
 
 // Dependencies names
 const (
+	// DIConfigLoader contains pagocore.ConfigLoader instance used to read DIConfigViper
+	DIConfigLoader = "pa_config_loader"
+
 	// DIConfigViper contains app config read to viper.Viper instance
 	DIConfigViper = "pa_config_viper"
 
 	// DIConfig contains initialized Config instance
 	DIConfig = "pa_config"
 
+	// DIServiceConfig contains initialized ServiceConfig instance, if it is registered with DIDefServiceConfig
+	DIServiceConfig = "pa_service_config"
+
 	// DII18n contains initialized i18n.TextsSource instance
 	// In case of renaming, see ginsrv/context.go:42
 	DII18n = "pa_i18n"
@@ -29,6 +37,9 @@ const (
 	// DIRouter contains gin router (gin.Engine) instance
 	DIRouter = "pa_router"
 
+	// DITracerProvider contains global sdktrace.TracerProvider instance, or nil if no tracing exporter provided in config
+	DITracerProvider = "pa_tracer_provider"
+
 	// DIMongo contains mongodb.MongoDB connection instance, or nil if no mongo host provided in config
 	DIMongo = "pa_mongo"
 
@@ -40,7 +51,7 @@ const (
 func GetDefaultDIBuilder() (*di.Builder, error) {
 	builder := &di.Builder{}
 
-	err := builder.Add(DIDefConfigViper(), DIDefConfig(), DIDefI18n(), DIDefRouter())
+	err := builder.Add(DIDefConfigLoader(), DIDefConfigViper(), DIDefConfig(), DIDefTracing(), DIDefI18n(), DIDefRouter())
 	if err != nil {
 		return nil, err
 	}
@@ -68,25 +79,62 @@ func BuildDefaultContainer() *di.Container {
 	return ctn
 }
 
-// DIDefConfigViper returns app config read from config file to the viper.Viper instance
+// DIDefConfigLoader returns pagocore.ConfigLoader with layers defined by pagocore.Opt
+func DIDefConfigLoader() di.Def {
+	return di.Def{
+		Name: DIConfigLoader,
+		Build: func(ctn *di.Container) (interface{}, error) {
+			return pagocore.NewConfigLoader(), nil
+		},
+	}
+}
+
+// DIDefConfigViper returns app config read by DIConfigLoader to the viper.Viper instance
 func DIDefConfigViper() di.Def {
 	return di.Def{
 		Name: DIConfigViper,
 		Build: func(ctn *di.Container) (interface{}, error) {
-			return pagocore.ReadConfig()
+			return DIGetConfigLoader(ctn).Load()
 		},
 	}
 }
 
-// DIDefConfig returns default Config dependency definition
+// DIDefConfig returns default Config dependency definition.
+// If DIServiceConfig is registered, its embedded Config is used.
 func DIDefConfig() di.Def {
 	return di.Def{
 		Name: DIConfig,
 		Build: func(ctn *di.Container) (interface{}, error) {
-			vpr := ctn.Get(DIConfigViper).(*viper.Viper)
+			if ctn.Has(DIServiceConfig) {
+				return di.Get[ServiceConfig](ctn, DIServiceConfig).GetConfig(), nil
+			}
 
 			conf := &Config{}
-			conf.SetFromViper(vpr)
+			err := LoadConfig(DIGetConfigViper(ctn), conf)
+			if err != nil {
+				return nil, err
+			}
+			conf.ApplyToGlobals()
+			logConfigDump(ctn, conf)
+
+			return conf, nil
+		},
+	}
+}
+
+// DIDefServiceConfig returns service-specific config dependency definition.
+// conf is loaded with LoadConfig and its embedded Config becomes available as DIConfig.
+// Untagged fields set on conf are kept by App.ReloadConfig, tagged fields are reloaded.
+func DIDefServiceConfig(conf ServiceConfig) di.Def {
+	return di.Def{
+		Name: DIServiceConfig,
+		Build: func(ctn *di.Container) (interface{}, error) {
+			err := LoadConfig(DIGetConfigViper(ctn), conf)
+			if err != nil {
+				return nil, err
+			}
+			conf.GetConfig().ApplyToGlobals()
+			logConfigDump(ctn, conf)
 
 			return conf, nil
 		},
@@ -98,12 +146,15 @@ func DIDefI18n() di.Def {
 	return di.Def{
 		Name: DII18n,
 		Build: func(ctn *di.Container) (interface{}, error) {
-			var err error
-			conf := ctn.Get(DIConfig).(*Config)
+			conf := DIGetConfig(ctn)
 			if conf.I18nFile != "" {
-				i18n.Source, err = i18n.NewSourceFromFile(conf.I18nFile)
+				src, err := i18n.NewSourceFromFile(conf.I18nFile)
+				if err != nil {
+					return nil, err
+				}
+				i18n.SetSource(src)
 			}
-			return i18n.Source, err
+			return i18n.GetSource(), nil
 		},
 	}
 }
@@ -119,13 +170,40 @@ func DIDefRouter() di.Def {
 	}
 }
 
+// DIDefTracing returns sdktrace.TracerProvider dependency definition, the provider is set global.
+// Returns nil if no Config.TracingExporter defined in config.
+func DIDefTracing() di.Def {
+	return di.Def{
+		Name: DITracerProvider,
+		Build: func(ctn *di.Container) (interface{}, error) {
+			conf := DIGetConfig(ctn)
+			if conf.TracingExporter == tracing.ExporterNone {
+				return nil, nil
+			}
+			return tracing.Init(context.Background(), &tracing.Options{
+				Exporter:     conf.TracingExporter,
+				OTLPEndpoint: conf.TracingOTLPEndpoint,
+				OTLPInsecure: conf.TracingOTLPInsecure,
+				SampleRatio:  conf.TracingSampleRatio,
+			})
+		},
+		Close: func(obj interface{}) error {
+			tp, ok := obj.(*sdktrace.TracerProvider)
+			if ok && tp != nil {
+				return tp.Shutdown(context.Background())
+			}
+			return nil
+		},
+	}
+}
+
 // DIDefMongo returns default mongodb.MongoDB dependency definition.
 // Returns nil if no Config.MongoHost defined in config.
 func DIDefMongo() di.Def {
 	return di.Def{
 		Name: DIMongo,
 		Build: func(ctn *di.Container) (interface{}, error) {
-			conf := ctn.Get(DIConfig).(*Config)
+			conf := DIGetConfig(ctn)
 			if conf.MongoHost == "" {
 				return nil, nil
 			}
@@ -144,6 +222,13 @@ func DIDefMongo() di.Def {
 			}
 			return nil
 		},
+		HealthCheck: func(ctx context.Context, obj interface{}) error {
+			m, ok := obj.(*mongodb.MongoDB)
+			if ok && m != nil {
+				return m.Ping(ctx)
+			}
+			return nil
+		},
 	}
 }
 
@@ -153,7 +238,7 @@ func DIDefRedis() di.Def {
 	return di.Def{
 		Name: DIRedis,
 		Build: func(ctn *di.Container) (interface{}, error) {
-			conf := ctn.Get(DIConfig).(*Config)
+			conf := DIGetConfig(ctn)
 			if conf.RedisHost == "" {
 				return nil, nil
 			}
@@ -181,32 +266,58 @@ func DIDefRedis() di.Def {
 			}
 			return nil
 		},
+		HealthCheck: func(ctx context.Context, obj interface{}) error {
+			client, ok := obj.(*redis.Client)
+			if ok && client != nil {
+				return client.Ping(ctx).Err()
+			}
+			return nil
+		},
 	}
 }
 
+// DIGetConfigLoader returns pagocore.ConfigLoader from the DI container
+func DIGetConfigLoader(ctn *di.Container) *pagocore.ConfigLoader {
+	return di.Get[*pagocore.ConfigLoader](ctn, DIConfigLoader)
+}
+
 // DIGetConfigViper returns config viper.Viper from the DI container
 func DIGetConfigViper(ctn *di.Container) *viper.Viper {
-	return ctn.Get(DIConfigViper).(*viper.Viper)
+	return di.Get[*viper.Viper](ctn, DIConfigViper)
 }
 
 // DIGetConfig returns Config from the DI container
 func DIGetConfig(ctn *di.Container) *Config {
-	return ctn.Get(DIConfig).(*Config)
+	return di.Get[*Config](ctn, DIConfig)
+}
+
+// DIGetServiceConfig returns ServiceConfig from the DI container,
+// or the Config if no service config is registered
+func DIGetServiceConfig(ctn *di.Container) ServiceConfig {
+	if ctn.Has(DIServiceConfig) {
+		return di.Get[ServiceConfig](ctn, DIServiceConfig)
+	}
+	return DIGetConfig(ctn)
 }
 
 // DIGetI18n returns i18n.TextsSource from the DI container
 func DIGetI18n(ctn *di.Container) *i18n.TextsSource {
-	return ctn.Get(DII18n).(*i18n.TextsSource)
+	return di.Get[*i18n.TextsSource](ctn, DII18n)
 }
 
 // DIGetRouter returns gin.Engine router from the DI container
 func DIGetRouter(ctn *di.Container) *gin.Engine {
-	return ctn.Get(DIRouter).(*gin.Engine)
+	return di.Get[*gin.Engine](ctn, DIRouter)
+}
+
+// DIGetTracerProvider returns sdktrace.TracerProvider from the DI container, or nil if tracing is disabled
+func DIGetTracerProvider(ctn *di.Container) *sdktrace.TracerProvider {
+	return di.Get[*sdktrace.TracerProvider](ctn, DITracerProvider)
 }
 
 // DIGetMongoDB returns mongodb.MongoDB from the DI container
 func DIGetMongoDB(ctn *di.Container) *mongodb.MongoDB {
-	m := ctn.Get(DIMongo).(*mongodb.MongoDB)
+	m := di.Get[*mongodb.MongoDB](ctn, DIMongo)
 	if m == nil {
 		log.Fatal(logTag, "attempt to access nil MongoDB instance")
 	}
@@ -215,9 +326,21 @@ func DIGetMongoDB(ctn *di.Container) *mongodb.MongoDB {
 
 // DIGetRedis returns redis.Client from the DI container
 func DIGetRedis(ctn *di.Container) *redis.Client {
-	r := ctn.Get(DIRedis).(*redis.Client)
+	r := di.Get[*redis.Client](ctn, DIRedis)
 	if r == nil {
 		log.Fatal(logTag, "attempt to access nil redis client instance")
 	}
 	return r
 }
+
+// logConfigDump writes effective config values with their sources to the debug log
+func logConfigDump(ctn *di.Container, conf interface{}) {
+	if !log.IsLevelEnabled(log.DebugLevel) || !ctn.Has(DIConfigLoader) {
+		return
+	}
+	loader := DIGetConfigLoader(ctn)
+	loader.TrackKeys(ConfigKeys(conf)...)
+	for _, v := range loader.Dump(DIGetConfigViper(ctn), ConfigSecretKeys(conf)...) {
+		log.Debug(logTag, "config ", v.Key, "=", v.Value, " (", v.Source, ")")
+	}
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"github.com/proactiongo/pagocore/di"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+// hookTimeoutDft is a default Hook timeout
+const hookTimeoutDft = 15 * time.Second
+
+// HookFn is an App lifecycle hook function
+type HookFn func(ctx context.Context, ctn *di.Container) error
+
+// Hook is an App lifecycle hook.
+// OnStart functions are called in order of registration before the server start,
+// OnStop functions are called in reverse order after the server shutdown.
+type Hook struct {
+	// Name is a hook name used in logs
+	Name string
+
+	// OnStart is called on App start. Error aborts the start.
+	OnStart HookFn
+
+	// OnStop is called on App stop, only if OnStart is succeeded
+	OnStop HookFn
+
+	// Timeout limits each of OnStart and OnStop calls, hookTimeoutDft if zero
+	Timeout time.Duration
+}
+
+// AddHooks registers App lifecycle hooks
+func (a *App) AddHooks(hooks ...Hook) {
+	a.hooks = append(a.hooks, hooks...)
+}
+
+// OnStart registers a hook to be called on App start
+func (a *App) OnStart(name string, fn HookFn) {
+	a.AddHooks(Hook{Name: name, OnStart: fn})
+}
+
+// OnStop registers a hook to be called on App stop
+func (a *App) OnStop(name string, fn HookFn) {
+	a.AddHooks(Hook{Name: name, OnStop: fn})
+}
+
+// startHooks calls OnStart of the registered hooks.
+// If one of them fails, already started hooks are stopped.
+func (a *App) startHooks(ctx context.Context) error {
+	for i, hook := range a.hooks {
+		if hook.OnStart == nil {
+			continue
+		}
+		log.Info(logTag, "starting hook ", hook.Name)
+		err := a.callHook(ctx, hook, hook.OnStart)
+		if err != nil {
+			log.Error(logTag, "failed to start hook ", hook.Name, ": ", err)
+			_ = a.stopHooks(a.hooks[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// stopHooks calls OnStop of the hooks in reverse order and returns the first error
+func (a *App) stopHooks(hooks []Hook) error {
+	var firstErr error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+		log.Info(logTag, "stopping hook ", hook.Name)
+		err := a.callHook(context.Background(), hook, hook.OnStop)
+		if err != nil {
+			log.Error(logTag, "failed to stop hook ", hook.Name, ": ", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// callHook calls the hook function with the hook timeout applied
+func (a *App) callHook(ctx context.Context, hook Hook, fn HookFn) error {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = hookTimeoutDft
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn(ctx, a.C())
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
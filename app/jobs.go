@@ -0,0 +1,402 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+	"math/big"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// jobLockTTLDft is a default Job leader lock TTL
+const jobLockTTLDft = 30 * time.Second
+
+// LogFieldJob is a log field with the job name
+const LogFieldJob = "job"
+
+// JobFn is a background job function. ctx is cancelled on the App shutdown or Job timeout.
+type JobFn func(ctx context.Context, ctn *di.Container) error
+
+// Job is a periodic background job run by the App.
+// Runs of the same job never overlap, the next run is scheduled after the previous one is finished.
+type Job struct {
+	// Name is a job name used in logs and in the leader lock key
+	Name string
+
+	// Interval runs the job with the fixed delay between runs
+	Interval time.Duration
+
+	// Cron runs the job by standard cron expression, e. g. "0 3 * * *" or "@hourly".
+	// Only one of Interval and Cron must be set.
+	Cron string
+
+	// RunOnStart runs the job right after the App start, then by schedule
+	RunOnStart bool
+
+	// Jitter is a maximal random delay added to each scheduled run
+	Jitter time.Duration
+
+	// Timeout limits each run, no limit if zero
+	Timeout time.Duration
+
+	// LeaderLock is a flag. If true, the job is run only on the replica holding the job lock, see JobLocker.
+	// The lock is held and renewed while the App is running, so the leader keeps running the job
+	// and another replica takes over within LockTTL after the leader is stopped or lost.
+	LeaderLock bool
+
+	// LockTTL is a leader lock TTL, jobLockTTLDft if zero. The lock is renewed every LockTTL/3.
+	LockTTL time.Duration
+
+	// Run is a job function
+	Run JobFn
+
+	schedule cron.Schedule
+}
+
+// JobLocker is a distributed lock which makes only one replica run the job
+type JobLocker interface {
+	// Lock acquires the lock or extends it if it is already held by the locker
+	Lock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+	// Unlock releases the lock if it is held by the locker
+	Unlock(ctx context.Context, key string) error
+}
+
+// AddJobs registers background jobs started after the App hooks registered before.
+// Jobs must be added before the App run.
+func (a *App) AddJobs(jobs ...Job) error {
+	for _, job := range jobs {
+		err := job.init()
+		if err != nil {
+			return err
+		}
+		if a.jobs == nil {
+			a.AddHooks(a.jobsHook())
+		}
+		a.jobs = append(a.jobs, job)
+	}
+	return nil
+}
+
+// SetJobLocker sets the leader lock of the jobs, NewRedisJobLocker with DIRedis client is used by default
+func (a *App) SetJobLocker(locker JobLocker) {
+	a.jobLocker = locker
+}
+
+// init validates the job and parses its schedule
+func (j *Job) init() error {
+	if j.Name == "" || j.Run == nil {
+		return errors.New(logTag + "job name and run function are required")
+	}
+	if (j.Interval > 0) == (j.Cron != "") {
+		return errors.New(logTag + "job `" + j.Name + "`: exactly one of interval and cron must be set")
+	}
+	if j.Cron != "" {
+		schedule, err := cron.ParseStandard(j.Cron)
+		if err != nil {
+			return fmt.Errorf("%sjob `%s`: invalid cron expression: %w", logTag, j.Name, err)
+		}
+		j.schedule = schedule
+	}
+	if j.LockTTL <= 0 {
+		j.LockTTL = jobLockTTLDft
+	}
+	return nil
+}
+
+// next returns the next scheduled run time after t
+func (j *Job) next(t time.Time) time.Time {
+	var next time.Time
+	if j.schedule != nil {
+		next = j.schedule.Next(t)
+	} else {
+		next = t.Add(j.Interval)
+	}
+	if j.Jitter > 0 {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(j.Jitter)))
+		if err == nil {
+			next = next.Add(time.Duration(n.Int64()))
+		}
+	}
+	return next
+}
+
+// jobsHook returns the App hook running the jobs until the App stop
+func (a *App) jobsHook() Hook {
+	var cancel context.CancelFunc
+	wg := &sync.WaitGroup{}
+
+	return Hook{
+		Name: "jobs",
+		OnStart: func(ctx context.Context, ctn *di.Container) error {
+			if a.jobLocker == nil && a.hasLeaderJobs() {
+				client, err := di.SafeGet[*redis.Client](ctn, DIRedis)
+				if err != nil {
+					return fmt.Errorf("%sredis is required for jobs leader lock: %w", logTag, err)
+				}
+				if client == nil {
+					return errors.New(logTag + "redis is required for jobs leader lock")
+				}
+				a.jobLocker = NewRedisJobLocker(client, "")
+			}
+
+			var runCtx context.Context
+			runCtx, cancel = context.WithCancel(context.Background())
+			for _, job := range a.jobs {
+				wg.Add(1)
+				go func(job Job) {
+					defer wg.Done()
+					a.runJobLoop(runCtx, job)
+				}(job)
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context, ctn *di.Container) error {
+			cancel()
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("%sjobs are not stopped: %w", logTag, ctx.Err())
+			}
+		},
+	}
+}
+
+// hasLeaderJobs checks if any of the jobs requires the leader lock
+func (a *App) hasLeaderJobs() bool {
+	for _, job := range a.jobs {
+		if job.LeaderLock {
+			return true
+		}
+	}
+	return false
+}
+
+// runJobLoop runs the job by schedule until ctx is done
+func (a *App) runJobLoop(ctx context.Context, job Job) {
+	logger := jobLogger(job)
+	var leader *jobLeader
+	if job.LeaderLock {
+		leader = newJobLeader(a.jobLocker, job)
+		defer leader.release()
+	}
+
+	next := job.next(time.Now())
+	if job.RunOnStart {
+		next = time.Now()
+	}
+	for {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if leader != nil && !leader.isLeader() {
+			logger.Debug(logTag, "job skipped, lock is held by another replica")
+			next = job.next(time.Now())
+			continue
+		}
+
+		a.runJob(ctx, job)
+		next = job.next(time.Now())
+	}
+}
+
+// runJob runs the job once, panic is recovered and logged
+func (a *App) runJob(ctx context.Context, job Job) {
+	logger := jobLogger(job)
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return job.Run(ctx, a.C())
+	}()
+
+	logger = logger.WithField("duration", time.Since(start).String())
+	if err != nil {
+		logger.Error(logTag, "job failed: ", err)
+		return
+	}
+	logger.Debug(logTag, "job finished")
+}
+
+// jobLogger returns the logger with the job fields
+func jobLogger(job Job) *log.Entry {
+	return log.WithFields(log.Fields{
+		pagocore.LogFieldType: pagocore.LogTypeApp,
+		LogFieldJob:           job.Name,
+	})
+}
+
+// jobLeader holds the job leader lock, acquiring or renewing it every LockTTL/3 until released
+type jobLeader struct {
+	locker JobLocker
+	key    string
+	ttl    time.Duration
+	logger *log.Entry
+
+	// leader is 1 if the lock is held
+	leader int32
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// newJobLeader tries to acquire the job lock and starts its renewal
+func newJobLeader(locker JobLocker, job Job) *jobLeader {
+	l := &jobLeader{
+		locker: locker,
+		key:    "pagocore:jobs:" + pagocore.Opt.ServiceName + ":" + job.Name,
+		ttl:    job.LockTTL,
+		logger: jobLogger(job),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	l.renew()
+	go l.run()
+	return l
+}
+
+// isLeader checks if the lock is held
+func (l *jobLeader) isLeader() bool {
+	return atomic.LoadInt32(&l.leader) == 1
+}
+
+// run renews the lock until the leader is released
+func (l *jobLeader) run() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.renew()
+		}
+	}
+}
+
+// renew acquires or extends the lock, the lock is considered lost if it can't be confirmed
+func (l *jobLeader) renew() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+	defer cancel()
+
+	ok, err := l.locker.Lock(ctx, l.key, l.ttl)
+	if err != nil {
+		l.logger.Error(logTag, "failed to acquire job lock: ", err)
+	}
+	var leader int32
+	if ok {
+		leader = 1
+	}
+	prev := atomic.SwapInt32(&l.leader, leader)
+	switch {
+	case prev == 0 && ok:
+		l.logger.Info(logTag, "job lock acquired")
+	case prev == 1 && !ok:
+		l.logger.Warn(logTag, "job lock is lost")
+	}
+}
+
+// release stops the renewal and releases the lock if it is held
+func (l *jobLeader) release() {
+	close(l.stop)
+	<-l.done
+	if !l.isLeader() {
+		return
+	}
+	err := l.locker.Unlock(context.Background(), l.key)
+	if err != nil {
+		l.logger.Error(logTag, "failed to release job lock: ", err)
+	}
+}
+
+// jobLockScript acquires the lock if it is free or extends it if it is held by the owner
+var jobLockScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner == false then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+if owner == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// jobUnlockScript deletes the lock if it is held by the owner
+var jobUnlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// NewRedisJobLocker creates JobLocker storing the locks in redis.
+// owner identifies the replica, random id prefixed with hostname is used if empty.
+func NewRedisJobLocker(client redis.Scripter, owner string) JobLocker {
+	if owner == "" {
+		owner = newJobLockOwner()
+	}
+	return &redisJobLocker{
+		client: client,
+		owner:  owner,
+	}
+}
+
+// redisJobLocker is a redis JobLocker implementation
+type redisJobLocker struct {
+	client redis.Scripter
+	owner  string
+}
+
+// Lock acquires or extends the lock
+func (l *redisJobLocker) Lock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	res, err := jobLockScript.Run(ctx, l.client, []string{key}, l.owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// Unlock releases the lock
+func (l *redisJobLocker) Unlock(ctx context.Context, key string) error {
+	return jobUnlockScript.Run(ctx, l.client, []string{key}, l.owner).Err()
+}
+
+// newJobLockOwner returns random lock owner id
+func newJobLockOwner() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return host + "-" + hex.EncodeToString(b)
+}
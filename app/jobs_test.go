@@ -0,0 +1,236 @@
+package app
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestApp_AddJobs(t *testing.T) {
+	run := func(ctx context.Context, ctn *di.Container) error {
+		return nil
+	}
+	a := NewApp(buildTestContainer(t, &Config{}))
+	assert.Error(t, a.AddJobs(Job{Interval: time.Second, Run: run}))
+	assert.Error(t, a.AddJobs(Job{Name: "no_run", Interval: time.Second}))
+	assert.Error(t, a.AddJobs(Job{Name: "no_schedule", Run: run}))
+	assert.Error(t, a.AddJobs(Job{Name: "both", Interval: time.Second, Cron: "@hourly", Run: run}))
+	assert.Error(t, a.AddJobs(Job{Name: "invalid_cron", Cron: "* *", Run: run}))
+	assert.NoError(t, a.AddJobs(
+		Job{Name: "interval", Interval: time.Second, Run: run},
+		Job{Name: "cron", Cron: "0 3 * * *", Run: run},
+	))
+	assert.Len(t, a.hooks, 1)
+
+	job := Job{Name: "cron", Cron: "0 3 * * *", Jitter: time.Minute, Run: run}
+	assert.NoError(t, job.init())
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	next := job.next(now)
+	assert.True(t, !next.Before(time.Date(2021, 1, 2, 3, 0, 0, 0, time.UTC)))
+	assert.True(t, next.Before(time.Date(2021, 1, 2, 3, 1, 0, 0, time.UTC)))
+	assert.Equal(t, jobLockTTLDft, job.LockTTL)
+}
+
+func TestApp_Jobs(t *testing.T) {
+	var runs, panics int32
+	cancelled := make(chan struct{})
+
+	conf := &Config{Port: getFreePort(t), ShutdownTimeout: time.Second}
+	a := NewApp(buildTestContainer(t, conf))
+	err := a.AddJobs(
+		Job{
+			Name:     "counter",
+			Interval: 10 * time.Millisecond,
+			Jitter:   5 * time.Millisecond,
+			Run: func(ctx context.Context, ctn *di.Container) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			},
+		},
+		Job{
+			Name:     "panic",
+			Interval: 10 * time.Millisecond,
+			Run: func(ctx context.Context, ctn *di.Container) error {
+				atomic.AddInt32(&panics, 1)
+				panic("expected panic")
+			},
+		},
+		Job{
+			Name:       "blocking",
+			Interval:   time.Hour,
+			RunOnStart: true,
+			Run: func(ctx context.Context, ctn *di.Container) error {
+				<-ctx.Done()
+				close(cancelled)
+				return ctx.Err()
+			},
+		},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- a.RunContext(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) >= 3 && atomic.LoadInt32(&panics) >= 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-runErr)
+	select {
+	case <-cancelled:
+	default:
+		t.Error("blocking job must be cancelled on shutdown")
+	}
+
+	stopped := atomic.LoadInt32(&runs)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&runs), "jobs must not run after shutdown")
+}
+
+func TestApp_Jobs_LeaderLock(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer func() {
+		_ = client.Close()
+	}()
+
+	mu := sync.Mutex{}
+	owners := make(map[string]int)
+	runs := func(owner string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return owners[owner]
+	}
+	newApp := func(owner string) *App {
+		a := NewApp(buildTestContainer(t, &Config{Port: getFreePort(t), ShutdownTimeout: time.Second}))
+		a.SetJobLocker(NewRedisJobLocker(client, owner))
+		err := a.AddJobs(Job{
+			Name:       "leader",
+			Interval:   200 * time.Millisecond,
+			LeaderLock: true,
+			LockTTL:    150 * time.Millisecond,
+			Run: func(ctx context.Context, ctn *di.Container) error {
+				mu.Lock()
+				owners[owner]++
+				mu.Unlock()
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	run := func(a *App) (context.CancelFunc, chan error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- a.RunContext(ctx)
+		}()
+		return cancel, runErr
+	}
+	// expires the lock unless it is renewed, miniredis never expires keys by itself
+	expire := func(d time.Duration) {
+		for end := time.Now().Add(d); time.Now().Before(end); {
+			time.Sleep(40 * time.Millisecond)
+			mr.FastForward(100 * time.Millisecond)
+		}
+	}
+
+	appA, appB := newApp("a"), newApp("b")
+	cancelA, errA := run(appA)
+	expire(100 * time.Millisecond)
+	cancelB, errB := run(appB)
+
+	// the interval is longer than the lock TTL, replicas started apart must not both run the job
+	expire(700 * time.Millisecond)
+	assert.GreaterOrEqual(t, runs("a"), 2)
+	assert.Zero(t, runs("b"), "only the leader must run the job")
+
+	cancelA()
+	assert.NoError(t, <-errA)
+	expire(500 * time.Millisecond)
+	assert.Greater(t, runs("b"), 0, "another replica must take over the lock")
+
+	cancelB()
+	assert.NoError(t, <-errB)
+	assert.Empty(t, mr.Keys(), "lock must be released on shutdown")
+}
+
+func TestApp_Jobs_LeaderLock_NoRedis(t *testing.T) {
+	job := Job{
+		Name:       "leader",
+		Interval:   time.Minute,
+		LeaderLock: true,
+		Run: func(ctx context.Context, ctn *di.Container) error {
+			return nil
+		},
+	}
+	nilRedis := di.Def{
+		Name: DIRedis,
+		Build: func(ctn *di.Container) (interface{}, error) {
+			return nil, nil
+		},
+	}
+
+	for _, defs := range [][]di.Def{nil, {nilRedis}} {
+		a := NewApp(buildTestContainer(t, &Config{Port: getFreePort(t), ShutdownTimeout: time.Second}, defs...))
+		assert.NoError(t, a.AddJobs(job))
+		err := a.RunContext(context.Background())
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "redis is required")
+		}
+	}
+}
+
+func TestRedisJobLocker(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer func() {
+		_ = client.Close()
+	}()
+
+	ctx := context.Background()
+	a := NewRedisJobLocker(client, "a")
+	b := NewRedisJobLocker(client, "b")
+
+	ok, err := a.Lock(ctx, "key", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = b.Lock(ctx, "key", time.Second)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	mr.FastForward(500 * time.Millisecond)
+	ok, err = a.Lock(ctx, "key", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	mr.FastForward(700 * time.Millisecond)
+	assert.True(t, mr.Exists("key"), "lock must be extended")
+
+	assert.NoError(t, b.Unlock(ctx, "key"))
+	assert.True(t, mr.Exists("key"))
+	assert.NoError(t, a.Unlock(ctx, "key"))
+	assert.False(t, mr.Exists("key"))
+
+	ok, err = b.Lock(ctx, "key", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	mr.FastForward(2 * time.Second)
+	ok, err = a.Lock(ctx, "key", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok, "expired lock must be acquired")
+}
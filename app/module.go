@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	log "github.com/sirupsen/logrus"
+)
+
+// Module is a self-contained App feature with its own dependencies and routes
+type Module interface {
+	// Name returns module name, used in logs and Config.DisabledModules
+	Name() string
+
+	// Defs returns module's dependencies definitions
+	Defs() []di.Def
+
+	// RegisterRoutes registers module's routes on the group rooted at pagocore.Opt.APIBasePath
+	RegisterRoutes(group *gin.RouterGroup, ctn *di.Container) error
+}
+
+// ModuleStarter is a Module with the hook called on App start
+type ModuleStarter interface {
+	OnStart(ctx context.Context, ctn *di.Container) error
+}
+
+// ModuleStopper is a Module with the hook called on App stop
+type ModuleStopper interface {
+	OnStop(ctx context.Context, ctn *di.Container) error
+}
+
+// AddModules registers App modules. Modules are mounted on App.Init() in order of registration.
+func (a *App) AddModules(modules ...Module) {
+	a.modules = append(a.modules, modules...)
+}
+
+// mountModules adds enabled modules' dependencies, routes and hooks to the App
+func (a *App) mountModules(router *gin.Engine) error {
+	conf := DIGetConfig(a.C())
+	for _, m := range a.modules {
+		if !conf.IsModuleEnabled(m.Name()) {
+			log.Info(logTag, "module ", m.Name(), " is disabled")
+			continue
+		}
+
+		err := a.C().Extend(m.Defs()...)
+		if err != nil {
+			log.Error(logTag, "failed to add module ", m.Name(), " dependencies: ", err)
+			return err
+		}
+
+		err = m.RegisterRoutes(router.Group(pagocore.Opt.APIBasePath), a.C())
+		if err != nil {
+			log.Error(logTag, "failed to register module ", m.Name(), " routes: ", err)
+			return err
+		}
+
+		hook := Hook{Name: "module " + m.Name()}
+		if s, ok := m.(ModuleStarter); ok {
+			hook.OnStart = s.OnStart
+		}
+		if s, ok := m.(ModuleStopper); ok {
+			hook.OnStop = s.OnStop
+		}
+		if hook.OnStart != nil || hook.OnStop != nil {
+			a.AddHooks(hook)
+		}
+
+		log.Info(logTag, "module ", m.Name(), " is mounted")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testModule struct {
+	name    string
+	started bool
+	stopped bool
+}
+
+func (m *testModule) Name() string {
+	return m.name
+}
+
+func (m *testModule) Defs() []di.Def {
+	return []di.Def{
+		{
+			Name: m.name + "_greeting",
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return "hello from " + m.name, nil
+			},
+		},
+	}
+}
+
+func (m *testModule) RegisterRoutes(group *gin.RouterGroup, ctn *di.Container) error {
+	group.GET("/"+m.name, func(c *gin.Context) {
+		c.String(http.StatusOK, di.Get[string](ctn, m.name+"_greeting"))
+	})
+	return nil
+}
+
+func (m *testModule) OnStart(ctx context.Context, ctn *di.Container) error {
+	m.started = true
+	return nil
+}
+
+func (m *testModule) OnStop(ctx context.Context, ctn *di.Container) error {
+	m.stopped = true
+	return nil
+}
+
+func TestApp_AddModules(t *testing.T) {
+	conf := &Config{Port: getFreePort(t), ShutdownTimeout: time.Second, DisabledModules: []string{"disabled"}}
+	enabled := &testModule{name: "enabled"}
+	disabled := &testModule{name: "disabled"}
+
+	a := NewApp(buildTestContainer(t, conf))
+	a.AddModules(enabled, disabled)
+	if !assert.NoError(t, a.Init()) {
+		return
+	}
+
+	router := DIGetRouter(a.C())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, pagocore.Opt.APIBasePath+"/enabled", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello from enabled", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, pagocore.Opt.APIBasePath+"/disabled", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.False(t, a.C().Has("disabled_greeting"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- a.RunContext(ctx)
+	}()
+	waitForPort(t, conf.Port)
+	cancel()
+	assert.NoError(t, <-runErr)
+
+	assert.True(t, enabled.started)
+	assert.True(t, enabled.stopped)
+	assert.False(t, disabled.started)
+}
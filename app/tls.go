@@ -0,0 +1,135 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"github.com/proactiongo/pagocore/utils"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"sync"
+)
+
+// tlsVersions maps config values to TLS versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newServerTLSConfig creates server TLS config reloading the certificate when its files change.
+// Returned stop function stops the files watching.
+func newServerTLSConfig(conf *Config) (*tls.Config, func(), error) {
+	if conf.TLSCertFile == "" || conf.TLSKeyFile == "" {
+		return nil, nil, errors.New("both tls_cert_file and tls_key_file are required")
+	}
+
+	minVersion, ok := tlsVersions[conf.TLSMinVersion]
+	if !ok {
+		return nil, nil, errors.New("unsupported tls_min_version: " + conf.TLSMinVersion)
+	}
+
+	ciphers, err := tlsCipherSuites(conf.TLSCipherSuites)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certs, err := newCertReloader(conf.TLSCertFile, conf.TLSKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConf := &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   ciphers,
+		GetCertificate: certs.GetCertificate,
+	}
+
+	if conf.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(conf.TLSClientCAFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, errors.New("no certificates found in tls_client_ca_file")
+		}
+		tlsConf.ClientCAs = pool
+		tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	stop, err := utils.WatchFiles([]string{conf.TLSCertFile, conf.TLSKeyFile}, func() {
+		err := certs.reload()
+		if err != nil {
+			log.Error(logTag, "failed to reload tls certificate: ", err)
+			return
+		}
+		log.Info(logTag, "tls certificate reloaded")
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tlsConf, stop, nil
+}
+
+// tlsCipherSuites returns IDs of the secure cipher suites by names
+func tlsCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+	ids := make([]uint16, len(names))
+	for i, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, errors.New("unsupported or insecure tls cipher suite: " + name)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// newCertReloader loads the certificate
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	err := r.reload()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// certReloader keeps the certificate loaded from files
+type certReloader struct {
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	certFile string
+	keyFile  string
+}
+
+// reload loads the certificate from files. Current certificate is kept on error.
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate returns current certificate, see tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
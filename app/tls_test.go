@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApp_RunContext_TLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := generateTestCert(t, "test ca", nil, nil)
+	srvCert, srvKey := generateTestCert(t, "server", ca, caKey)
+	clientCert, clientKey := generateTestCert(t, "client", ca, caKey)
+
+	conf := &Config{
+		Port:            getFreePort(t),
+		ShutdownTimeout: time.Second,
+		TLSCertFile:     filepath.Join(dir, "tls.crt"),
+		TLSKeyFile:      filepath.Join(dir, "tls.key"),
+		TLSClientCAFile: filepath.Join(dir, "ca.crt"),
+		TLSMinVersion:   "1.2",
+	}
+	writeTestCert(t, conf.TLSCertFile, conf.TLSKeyFile, srvCert, srvKey)
+	writeTestCert(t, conf.TLSClientCAFile, "", ca, nil)
+
+	stop := runTestApp(t, conf)
+	defer stop()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	tlsConf := &tls.Config{
+		RootCAs: pool,
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{clientCert.Raw},
+			PrivateKey:  clientKey,
+		}},
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf.Clone(), ForceAttemptHTTP2: true}}
+	url := "https://localhost:" + conf.Port + "/ping"
+
+	resp, err := client.Get(url)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor)
+	assert.Equal(t, "server", resp.TLS.PeerCertificates[0].Subject.CommonName)
+
+	noCertClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	_, err = noCertClient.Get(url)
+	assert.Error(t, err)
+
+	rotated, rotatedKey := generateTestCert(t, "rotated", ca, caKey)
+	writeTestCert(t, conf.TLSCertFile, conf.TLSKeyFile, rotated, rotatedKey)
+	assert.Eventually(t, func() bool {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf.Clone()}}
+		resp, err := client.Get(url)
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].Subject.CommonName == "rotated"
+	}, 3*time.Second, 50*time.Millisecond)
+}
+
+func TestApp_RunContext_H2C(t *testing.T) {
+	conf := &Config{Port: getFreePort(t), ShutdownTimeout: time.Second, H2C: true}
+	stop := runTestApp(t, conf)
+	defer stop()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp, err := client.Get("http://127.0.0.1:" + conf.Port + "/ping")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor)
+}
+
+func TestApp_RunContext_TLSInvalid(t *testing.T) {
+	conf := &Config{Port: getFreePort(t), ShutdownTimeout: time.Second, TLSCertFile: "missing.crt", TLSMinVersion: "1.2"}
+	a := NewApp(buildTestContainer(t, conf))
+	assert.Error(t, a.RunContext(context.Background()))
+}
+
+// runTestApp runs the App with /ping route and returns the function to stop it
+func runTestApp(t *testing.T, conf *Config) func() {
+	a := NewApp(buildTestContainer(t, conf))
+	a.SetPrepareRouterFn(func(router *gin.Engine, ctn *di.Container) error {
+		router.GET("/ping", func(c *gin.Context) {
+			c.String(http.StatusOK, "pong")
+		})
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- a.RunContext(ctx)
+	}()
+	waitForPort(t, conf.Port)
+
+	return func() {
+		cancel()
+		assert.NoError(t, <-runErr)
+	}
+}
+
+// generateTestCert generates a certificate signed by the parent, or self-signed CA if parent is nil
+func generateTestCert(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if parent == nil {
+		tpl.IsCA = true
+		tpl.BasicConstraintsValid = true
+		tpl.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = tpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// writeTestCert writes PEM-encoded certificate and key files, key is skipped if keyPath is empty
+func writeTestCert(t *testing.T, certPath, keyPath string, cert *x509.Certificate, key *ecdsa.PrivateKey) {
+	err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyPath == "" {
+		return
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,247 @@
+package pagocore
+
+import (
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Config layers, from the lowest priority to the highest
+const (
+	ConfigLayerDefault = "default"
+	ConfigLayerFile    = "file"
+	ConfigLayerEnv     = "env"
+	ConfigLayerFlag    = "flag"
+)
+
+// configSecretMask replaces secret values in the config dump
+const configSecretMask = "******"
+
+// configSecretWords mark keys as secret in the config dump
+var configSecretWords = []string{"password", "secret", "token"}
+
+// ReadConfig loads configuration from the layers defined by Opt to the viper instance
+func ReadConfig() (*viper.Viper, error) {
+	return NewConfigLoader().Load()
+}
+
+// NewConfigLoader creates ConfigLoader with layers defined by Opt
+func NewConfigLoader() *ConfigLoader {
+	files := []string{Opt.ConfigFilePath}
+	files = append(files, Opt.ConfigExtraFiles...)
+	return &ConfigLoader{
+		Defaults:  Opt.ConfigDefaults,
+		Files:     files,
+		EnvPrefix: Opt.ConfigEnvPrefix,
+		Flags:     Opt.ConfigFlags,
+	}
+}
+
+// ConfigLoader loads configuration from the layers: defaults, files in order,
+// environment variables and command-line flags. Each next layer overrides the previous ones.
+type ConfigLoader struct {
+	// Defaults are default config values
+	Defaults map[string]interface{}
+
+	// Files are paths to env, yaml or json config files, skipped if missing
+	Files []string
+
+	// EnvPrefix is a prefix of environment variables, e. g. "APP" for APP_SERVICE_PORT
+	EnvPrefix string
+
+	// Flags are command-line flags, only changed flags override the values
+	Flags *pflag.FlagSet
+
+	// sources contains the layer each effective value came from
+	sources map[string]string
+	mu      sync.RWMutex
+}
+
+// ConfigValue is an effective config value with its source layer
+type ConfigValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Load reads all layers to the viper instance
+func (l *ConfigLoader) Load() (*viper.Viper, error) {
+	conf := viper.New()
+	sources := make(map[string]string)
+
+	for key, val := range l.Defaults {
+		conf.SetDefault(key, val)
+		sources[strings.ToLower(key)] = ConfigLayerDefault
+	}
+
+	for _, path := range l.Files {
+		if path == "" {
+			continue
+		}
+		fileConf, err := readConfigFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Debug("[pagocore] config file is not found, skipping: ", path)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = conf.MergeConfigMap(fileConf.AllSettings())
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range fileConf.AllKeys() {
+			sources[key] = ConfigLayerFile + ":" + path
+		}
+	}
+
+	conf.SetEnvPrefix(l.EnvPrefix)
+	conf.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	conf.AutomaticEnv()
+	if l.EnvPrefix != "" {
+		prefix := strings.ToUpper(l.EnvPrefix) + "_"
+		for _, env := range os.Environ() {
+			name := strings.SplitN(env, "=", 2)[0]
+			if strings.HasPrefix(name, prefix) {
+				sources[strings.ToLower(strings.TrimPrefix(name, prefix))] = ConfigLayerEnv
+			}
+		}
+	}
+	for key := range sources {
+		if l.isEnvSet(key) {
+			sources[key] = ConfigLayerEnv
+		}
+	}
+
+	if l.Flags != nil {
+		err := conf.BindPFlags(l.Flags)
+		if err != nil {
+			return nil, err
+		}
+		l.Flags.Visit(func(f *pflag.Flag) {
+			sources[strings.ToLower(f.Name)] = ConfigLayerFlag
+		})
+	}
+
+	l.mu.Lock()
+	l.sources = sources
+	l.mu.Unlock()
+	return conf, nil
+}
+
+// TrackKeys adds the keys set only by environment variables to the known keys, e. g. the config schema keys.
+// Without EnvPrefix environment variables can't be listed, so the sources of such keys are unknown until tracked.
+func (l *ConfigLoader) TrackKeys(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.sources == nil {
+		l.sources = make(map[string]string)
+	}
+	for _, key := range keys {
+		key = strings.ToLower(key)
+		if _, ok := l.sources[key]; !ok && l.isEnvSet(key) {
+			l.sources[key] = ConfigLayerEnv
+		}
+	}
+}
+
+// Source returns the layer the effective value of the key came from, or empty string if the key is unknown
+func (l *ConfigLoader) Source(key string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.sources[strings.ToLower(key)]
+}
+
+// Dump returns effective values of the known keys sorted by key.
+// Values of secretKeys and keys containing password, secret or token words are masked.
+func (l *ConfigLoader) Dump(conf *viper.Viper, secretKeys ...string) []*ConfigValue {
+	secrets := make(map[string]bool, len(secretKeys))
+	for _, key := range secretKeys {
+		secrets[strings.ToLower(key)] = true
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	keys := make([]string, 0, len(l.sources))
+	for key := range l.sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := make([]*ConfigValue, len(keys))
+	for i, key := range keys {
+		val := cast.ToString(conf.Get(key))
+		if val != "" && (secrets[key] || isSecretConfigKey(key)) {
+			val = configSecretMask
+		}
+		values[i] = &ConfigValue{
+			Key:    key,
+			Value:  val,
+			Source: l.sources[key],
+		}
+	}
+	return values
+}
+
+// envName returns environment variable name of the key
+func (l *ConfigLoader) envName(key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if l.EnvPrefix != "" {
+		return strings.ToUpper(l.EnvPrefix) + "_" + name
+	}
+	return name
+}
+
+// isEnvSet checks if the environment variable of the key is set and not empty
+func (l *ConfigLoader) isEnvSet(key string) bool {
+	v, ok := os.LookupEnv(l.envName(key))
+	return ok && v != ""
+}
+
+// readConfigFile reads config file to the new viper instance
+func readConfigFile(path string) (*viper.Viper, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	conf := viper.New()
+	conf.SetConfigFile(path)
+	conf.SetConfigType(configFileType(path))
+
+	err := conf.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// configFileType returns config type by the file extension
+func configFileType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".env":
+		return "env"
+	case ".yml", ".yaml":
+		return "yaml"
+	case ".json":
+		return "json"
+	}
+	return Opt.ConfigFileType
+}
+
+// isSecretConfigKey checks if the key name looks like a secret
+func isSecretConfigKey(key string) bool {
+	for _, word := range configSecretWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
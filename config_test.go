@@ -0,0 +1,90 @@
+package pagocore_test
+
+import (
+	"github.com/proactiongo/pagocore"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigLoader_Load(t *testing.T) {
+	dir := t.TempDir()
+	yml := filepath.Join(dir, "config.yml")
+	jsn := filepath.Join(dir, "config.json")
+	assert.NoError(t, os.WriteFile(yml, []byte("service_port: 80\nredis_host: yaml_host\nmongo_db: yaml_db\n"), 0644))
+	assert.NoError(t, os.WriteFile(jsn, []byte(`{"redis_host": "json_host", "db_password": "qwerty"}`), 0644))
+
+	t.Setenv("TEST_MONGO_DB", "env_db")
+	t.Setenv("TEST_ONLY_ENV", "env_val")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("service_port", "", "")
+	flags.String("log_level", "info", "")
+	assert.NoError(t, flags.Parse([]string{"--service_port=8080"}))
+
+	l := &pagocore.ConfigLoader{
+		Defaults:  map[string]interface{}{"log_level": "warn", "redis_db": 1},
+		Files:     []string{yml, filepath.Join(dir, "missing.env"), jsn},
+		EnvPrefix: "test",
+		Flags:     flags,
+	}
+	conf, err := l.Load()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "8080", conf.GetString("service_port"))
+	assert.Equal(t, pagocore.ConfigLayerFlag, l.Source("service_port"))
+	assert.Equal(t, "warn", conf.GetString("log_level"))
+	assert.Equal(t, pagocore.ConfigLayerDefault, l.Source("log_level"))
+	assert.Equal(t, "json_host", conf.GetString("redis_host"))
+	assert.Equal(t, pagocore.ConfigLayerFile+":"+jsn, l.Source("redis_host"))
+	assert.Equal(t, "env_db", conf.GetString("mongo_db"))
+	assert.Equal(t, pagocore.ConfigLayerEnv, l.Source("mongo_db"))
+	assert.Equal(t, "env_val", conf.GetString("only_env"))
+
+	dump := map[string]*pagocore.ConfigValue{}
+	for _, v := range l.Dump(conf, "redis_host") {
+		dump[v.Key] = v
+	}
+	assert.Equal(t, "******", dump["redis_host"].Value)
+	assert.Equal(t, "******", dump["db_password"].Value)
+	assert.Equal(t, "env_db", dump["mongo_db"].Value)
+	assert.Equal(t, pagocore.ConfigLayerEnv, dump["only_env"].Source)
+
+	l.Files = []string{filepath.Join(dir, "config.json"), dir}
+	_, err = l.Load()
+	assert.Error(t, err)
+}
+
+func TestConfigLoader_TrackKeys(t *testing.T) {
+	t.Setenv("PAGOCORE_TEST_ENV_KEY", "env_val")
+	t.Setenv("PAGOCORE_TEST_FLAG_KEY", "env_val")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("pagocore_test_flag_key", "", "")
+	assert.NoError(t, flags.Parse([]string{"--pagocore_test_flag_key=flag_val"}))
+
+	l := &pagocore.ConfigLoader{Flags: flags}
+	conf, err := l.Load()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "", l.Source("pagocore_test_env_key"))
+
+	l.TrackKeys("pagocore_test_env_key", "pagocore_test_flag_key", "pagocore_test_missing_key")
+	assert.Equal(t, pagocore.ConfigLayerEnv, l.Source("pagocore_test_env_key"))
+	assert.Equal(t, pagocore.ConfigLayerFlag, l.Source("pagocore_test_flag_key"))
+	assert.Equal(t, "", l.Source("pagocore_test_missing_key"))
+
+	dump := map[string]*pagocore.ConfigValue{}
+	for _, v := range l.Dump(conf) {
+		dump[v.Key] = v
+	}
+	if assert.Contains(t, dump, "pagocore_test_env_key") {
+		assert.Equal(t, "env_val", dump["pagocore_test_env_key"].Value)
+	}
+	assert.NotContains(t, dump, "pagocore_test_missing_key")
+}
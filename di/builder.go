@@ -7,7 +7,6 @@ import (
 // Builder is a Container builder
 type Builder struct {
 	ctn *Container
-	ord []string
 }
 
 // Add adds Def to the Container
@@ -23,31 +22,99 @@ func (b *Builder) Add(defs ...Def) error {
 			}
 		}
 		b.ctn.defs[def.Name] = def
-		b.ord = append(b.ord, def.Name)
+		b.ctn.ord = append(b.ctn.ord, def.Name)
 	}
 	return nil
 }
 
-// Build prepares Container and builds non-lazy definitions
+// Override replaces registered definitions keeping their build order.
+// Decorators of the replaced definitions are dropped.
+func (b *Builder) Override(defs ...Def) error {
+	b.initContainer()
+	for _, def := range defs {
+		if _, exists := b.ctn.defs[def.Name]; !exists {
+			return errors.New("[pagocore.di] definition with name `" + def.Name + "` is not registered")
+		}
+		if def.Validate != nil {
+			if err := def.Validate(b.ctn); err != nil {
+				return err
+			}
+		}
+		b.ctn.defs[def.Name] = def
+	}
+	return nil
+}
+
+// Decorate wraps the built object of the registered definition.
+// Close and HealthCheck functions of the definition still receive the original object.
+func (b *Builder) Decorate(name string, fn DecorateFn) error {
+	b.initContainer()
+	def, exists := b.ctn.defs[name]
+	if !exists {
+		return errors.New("[pagocore.di] definition with name `" + name + "` is not registered")
+	}
+	def.decorators = append(def.decorators[:len(def.decorators):len(def.decorators)], fn)
+	b.ctn.defs[name] = def
+	return nil
+}
+
+// Build prepares Container and builds non-lazy definitions.
+// Definitions may Get each other regardless of their order.
 func (b *Builder) Build() (*Container, error) {
 	b.initContainer()
-	for _, name := range b.ord {
-		def := b.ctn.defs[name]
-		if !def.Lazy {
-			if err := def.build(b.ctn); err != nil {
+	for _, name := range b.ctn.ord {
+		def, _ := b.ctn.def(name)
+		if !def.Lazy && def.Scope == ScopeApp {
+			if _, err := b.ctn.SafeGet(name); err != nil {
 				return nil, err
 			}
-			b.ctn.defs[name] = def
 		}
 	}
 	return b.ctn, nil
 }
 
+// Extend adds definitions to the built Container and builds non-lazy ones
+func (c *Container) Extend(defs ...Def) error {
+	if c.parent != nil {
+		return errors.New("[pagocore.di] sub-container can not be extended")
+	}
+	for _, def := range defs {
+		if c.Has(def.Name) {
+			return errors.New("[pagocore.di] definition with name `" + def.Name + "` already exists")
+		}
+		if def.Validate != nil {
+			if err := def.Validate(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	c.mu.Lock()
+	for _, def := range defs {
+		if _, exists := c.defs[def.Name]; exists {
+			c.mu.Unlock()
+			return errors.New("[pagocore.di] definition with name `" + def.Name + "` already exists")
+		}
+	}
+	for _, def := range defs {
+		c.defs[def.Name] = def
+		c.ord = append(c.ord, def.Name)
+	}
+	c.mu.Unlock()
+
+	for _, def := range defs {
+		if !def.Lazy && def.Scope == ScopeApp {
+			if _, err := c.SafeGet(def.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // initContainer creates container instance
 func (b *Builder) initContainer() {
 	if b.ctn == nil {
-		b.ctn = &Container{
-			defs: make(DefsMap),
-		}
+		b.ctn = newContainer()
 	}
 }
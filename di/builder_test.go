@@ -1,6 +1,7 @@
 package di_test
 
 import (
+	"context"
 	"errors"
 	"github.com/proactiongo/pagocore/di"
 	"github.com/stretchr/testify/assert"
@@ -107,7 +108,9 @@ func TestBuilder_Build(t *testing.T) {
 		ctn.Get("unknown_key")
 	})
 
-	ctn.Close()
+	err = ctn.Close(context.Background())
+	assert.Error(t, err)
+	assert.NoError(t, ctn.Close(context.Background()))
 
 	b = &di.Builder{}
 	err = b.Add(
@@ -132,3 +135,80 @@ func TestBuilder_Build(t *testing.T) {
 	_, err = b.Build()
 	assert.Error(t, err)
 }
+
+func TestBuilder_Override(t *testing.T) {
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: "test1",
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return "real", nil
+			},
+		},
+	)
+	assert.NoError(t, err)
+
+	err = b.Override(di.Def{Name: "unknown"})
+	assert.Error(t, err)
+
+	err = b.Override(
+		di.Def{
+			Name: "test1",
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return "fake", nil
+			},
+		},
+	)
+	assert.NoError(t, err)
+
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "fake", ctn.Get("test1"))
+}
+
+func TestBuilder_Decorate(t *testing.T) {
+	var closed interface{}
+	var checked interface{}
+
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: "test1",
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return "val", nil
+			},
+			Close: func(obj interface{}) error {
+				closed = obj
+				return nil
+			},
+			HealthCheck: func(ctx context.Context, obj interface{}) error {
+				checked = obj
+				return nil
+			},
+		},
+	)
+	assert.NoError(t, err)
+
+	assert.Error(t, b.Decorate("unknown", nil))
+	for _, suffix := range []string{"_a", "_b"} {
+		suffix := suffix
+		err = b.Decorate("test1", func(ctn *di.Container, obj interface{}) (interface{}, error) {
+			return obj.(string) + suffix, nil
+		})
+		assert.NoError(t, err)
+	}
+
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "val_a_b", ctn.Get("test1"))
+
+	ctn.CheckHealth(context.Background())
+	assert.Equal(t, "val", checked)
+
+	assert.NoError(t, ctn.Close(context.Background()))
+	assert.Equal(t, "val", closed)
+}
@@ -1,18 +1,80 @@
 package di
 
 import (
+	"context"
 	"errors"
 	log "github.com/sirupsen/logrus"
+	"sync"
 )
 
-// Container is a dependency container
+// Container is a dependency container.
+// It is safe for concurrent use.
 type Container struct {
+	*store
+
+	// chain is a build chain of the handle passed to the Build function, nil for the top-level handle
+	chain *buildChain
+}
+
+// pendingBuild is an in-progress build, its result is shared with concurrent callers
+type pendingBuild struct {
+	done chan struct{}
+	obj  interface{}
+	err  error
+}
+
+// buildChain is a list of definitions names being built by the current call chain.
+// It is done when the build of the last definition returns, so the handle kept by
+// the built dependency does not record later calls as build edges.
+type buildChain struct {
+	names []string
+	done  bool
+}
+
+// store is a Container's state shared between its handles
+type store struct {
+	mu   sync.Mutex
 	defs DefsMap
+
+	// ord is a list of definitions names in order of registration
+	ord []string
+
+	// built is a list of built definitions names in order of build
+	built []string
+
+	// deps contains dependency edges discovered during builds
+	deps map[string][]string
+
+	// pending contains in-progress builds
+	pending map[string]*pendingBuild
+
+	closed bool
+
+	// parent is a parent Container of the scoped sub-container
+	parent *Container
+	scope  Scope
+	ctx    context.Context
+}
+
+// newContainer creates an empty Container
+func newContainer() *Container {
+	return &Container{
+		store: &store{
+			defs:    make(DefsMap),
+			deps:    make(map[string][]string),
+			pending: make(map[string]*pendingBuild),
+		},
+	}
 }
 
 // Has checks if dependency is registered in Container
 func (c *Container) Has(name string) bool {
+	c.mu.Lock()
 	_, ok := c.defs[name]
+	c.mu.Unlock()
+	if !ok && c.parent != nil {
+		return c.parent.Has(name)
+	}
 	return ok
 }
 
@@ -25,34 +87,201 @@ func (c *Container) Get(name string) interface{} {
 	return obj
 }
 
-// SafeGet returns built dependency
+// SafeGet returns built dependency.
+// Dependency is built only once, concurrent callers wait for the build and get its result.
+// Failed build is retried by the next call after the concurrent callers got its error.
+// Returns ErrDependencyCycle error if the dependency is required during its own build.
 func (c *Container) SafeGet(name string) (interface{}, error) {
+	c.mu.Lock()
 	def, ok := c.defs[name]
 	if !ok {
+		c.mu.Unlock()
+		if c.parent != nil {
+			return c.parent.SafeGet(name)
+		}
 		return nil, errors.New("[pagocore.di] dependency is not registered: " + name)
 	}
-	if def.Lazy {
-		err := def.build(c)
-		if err != nil {
-			return nil, err
+	if def.Scope != c.scope {
+		c.mu.Unlock()
+		return nil, errors.New("[pagocore.di] dependency `" + name + "` is available only in the `" + string(def.Scope) + "` scope")
+	}
+
+	chain := c.activeChain()
+	if len(chain) > 0 {
+		c.addDep(chain[len(chain)-1], name)
+	}
+	for i, n := range chain {
+		if n == name {
+			c.mu.Unlock()
+			return nil, newCycleError(append(chain[i:len(chain):len(chain)], name))
 		}
+	}
+
+	if def.built {
+		c.mu.Unlock()
+		return def.obj, def.err
+	}
+
+	wait, ok := c.pending[name]
+	if ok {
+		if len(chain) > 0 {
+			// the dependency is being built concurrently, waiting for it is a deadlock
+			// if it depends on the definition being built by the current call chain
+			path := c.depsPath(name, chain[len(chain)-1])
+			if path != nil {
+				c.mu.Unlock()
+				return nil, newCycleError(append([]string{chain[len(chain)-1]}, path...))
+			}
+		}
+		c.mu.Unlock()
+		<-wait.done
+		return wait.obj, wait.err
+	}
+
+	wait = &pendingBuild{done: make(chan struct{})}
+	c.pending[name] = wait
+	c.mu.Unlock()
+
+	handle := c.withChain(chain, name)
+	def.build(handle)
+
+	c.mu.Lock()
+	handle.chain.done = true
+	c.setBuilt(def)
+	delete(c.pending, name)
+	c.mu.Unlock()
+	wait.obj, wait.err = def.obj, def.err
+	close(wait.done)
+
+	return def.obj, def.err
+}
+
+// Replace swaps the object of the registered dependency, e. g. the reloaded config.
+// The object is not decorated and it is closed with the Container instead of the previous one.
+// Callers which already got the previous object keep using it, the previous object is not closed.
+// Returns an error if the dependency is being built.
+func (c *Container) Replace(name string, obj interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	def, ok := c.defs[name]
+	if !ok {
+		return errors.New("[pagocore.di] dependency is not registered: " + name)
+	}
+	if _, ok := c.pending[name]; ok {
+		return errors.New("[pagocore.di] dependency is being built: " + name)
+	}
+	wasBuilt := def.built && def.err == nil
+	def.obj, def.raw, def.err, def.built = obj, obj, nil, true
+	if wasBuilt {
 		c.defs[name] = def
+	} else {
+		c.setBuilt(def)
 	}
-	return def.obj, nil
+	return nil
 }
 
-// Close finalizes dependencies
-func (c *Container) Close() {
-	for _, def := range c.defs {
-		if !def.built {
-			continue
-		}
+// Close finalizes dependencies in reverse order of their build.
+// Returns CloseError with all failed dependencies, including the ones
+// not closed before ctx is done. Subsequent calls do nothing.
+func (c *Container) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	defs := make([]Def, len(c.built))
+	for i, name := range c.built {
+		defs[i] = c.defs[name]
+	}
+	c.mu.Unlock()
+
+	closeErr := &CloseError{}
+	for i := len(defs) - 1; i >= 0; i-- {
+		def := defs[i]
 		if def.Close == nil {
 			continue
 		}
-		err := def.Close(def.obj)
+		err := def.close(ctx)
 		if err != nil {
 			log.Error("[pagocore.di] failed to close dependency: ", err)
+			closeErr.Errs = append(closeErr.Errs, err)
+		}
+	}
+
+	if len(closeErr.Errs) > 0 {
+		return closeErr
+	}
+	return nil
+}
+
+// withChain returns the Container handle with the definition name added to the active build chain
+func (c *Container) withChain(chain []string, name string) *Container {
+	names := make([]string, len(chain), len(chain)+1)
+	copy(names, chain)
+	return &Container{
+		store: c.store,
+		chain: &buildChain{names: append(names, name)},
+	}
+}
+
+// activeChain returns the build chain names if the build is in progress. Must be called under the lock.
+func (c *Container) activeChain() []string {
+	if c.chain == nil || c.chain.done {
+		return nil
+	}
+	return c.chain.names
+}
+
+// addDep remembers dependency edge. Must be called under the lock.
+func (c *Container) addDep(from, to string) {
+	for _, dep := range c.deps[from] {
+		if dep == to {
+			return
+		}
+	}
+	c.deps[from] = append(c.deps[from], to)
+}
+
+// depsPath returns a path of dependency edges from one definition to another,
+// or nil if there is no path. Must be called under the lock.
+func (c *Container) depsPath(from, to string) []string {
+	visited := make(map[string]bool)
+	var walk func(name string) []string
+	walk = func(name string) []string {
+		if name == to {
+			return []string{name}
+		}
+		if visited[name] {
+			return nil
 		}
+		visited[name] = true
+		for _, dep := range c.deps[name] {
+			if path := walk(dep); path != nil {
+				return append([]string{name}, path...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+// def returns the definition by name
+func (c *Container) def(name string) (Def, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	def, ok := c.defs[name]
+	return def, ok
+}
+
+// setBuilt stores built definition and remembers its build order if the build is succeeded.
+// Failed build is not cached, its error is kept for the Graph and the next call rebuilds the definition.
+func (c *Container) setBuilt(def Def) {
+	if def.err != nil {
+		def.built, def.obj, def.raw = false, nil, nil
+	}
+	c.defs[def.Name] = def
+	if def.err == nil {
+		c.built = append(c.built, def.Name)
 	}
 }
@@ -0,0 +1,265 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestContainer_SafeGet_Concurrent(t *testing.T) {
+	var builds int32
+
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: "lazy",
+			Lazy: true,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				atomic.AddInt32(&builds, 1)
+				time.Sleep(50 * time.Millisecond)
+				return &struct{}{}, nil
+			},
+		},
+		di.Def{
+			Name: "lazy_err",
+			Lazy: true,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				atomic.AddInt32(&builds, 1)
+				time.Sleep(50 * time.Millisecond)
+				return nil, errors.New("expected error")
+			},
+		},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	const n = 20
+	objs := make([]interface{}, n)
+	errs := make([]error, n)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			objs[i] = ctn.Get("lazy")
+			_, errs[i] = ctn.SafeGet("lazy_err")
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&builds))
+	for i := 1; i < n; i++ {
+		assert.Same(t, objs[0], objs[i])
+		assert.Same(t, errs[0], errs[i])
+	}
+	assert.Error(t, errs[0])
+}
+
+func TestContainer_SafeGet_Retry(t *testing.T) {
+	var builds int32
+
+	b := &di.Builder{}
+	err := b.Add(di.Def{
+		Name: "flaky",
+		Lazy: true,
+		Build: func(ctn *di.Container) (interface{}, error) {
+			if atomic.AddInt32(&builds, 1) == 1 {
+				return nil, errors.New("expected error")
+			}
+			return "ok", nil
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = ctn.SafeGet("flaky")
+	assert.Error(t, err)
+	assert.Equal(t, "expected error", ctn.Graph().Nodes[0].Error)
+
+	obj, err := ctn.SafeGet("flaky")
+	assert.NoError(t, err, "failed build must be retried")
+	assert.Equal(t, "ok", obj)
+	assert.True(t, ctn.Graph().Nodes[0].Built)
+
+	_, _ = ctn.SafeGet("flaky")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&builds), "succeeded build must not be repeated")
+}
+
+func TestContainer_SafeGet_Cycle(t *testing.T) {
+	get := func(name string) di.BuildFn {
+		return func(ctn *di.Container) (interface{}, error) {
+			return ctn.Get(name), nil
+		}
+	}
+
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{Name: "a", Build: get("b")},
+		di.Def{Name: "b", Build: get("c")},
+		di.Def{Name: "c", Build: get("a")},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = b.Build()
+	assert.ErrorIs(t, err, di.ErrDependencyCycle)
+	assert.Contains(t, err.Error(), "a -> b -> c -> a")
+}
+
+func TestContainer_SafeGet_Locator(t *testing.T) {
+	type locator struct {
+		ctn *di.Container
+	}
+
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: "loc",
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return &locator{ctn: ctn}, nil
+			},
+		},
+		di.Def{
+			Name: "x",
+			Lazy: true,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return "x", nil
+			},
+		},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	loc := ctn.Get("loc").(*locator)
+	_, err = loc.ctn.SafeGet("loc")
+	assert.NoError(t, err, "the stored handle must not report the finished build as a cycle")
+	_, err = loc.ctn.SafeGet("x")
+	assert.NoError(t, err)
+
+	for _, node := range ctn.Graph().Nodes {
+		if node.Name == "loc" {
+			assert.Empty(t, node.Deps, "calls after the build must not be recorded as build edges")
+		}
+	}
+}
+
+func TestContainer_Close(t *testing.T) {
+	var closed []string
+	def := func(name string, dep string, closeErr error, delay time.Duration) di.Def {
+		return di.Def{
+			Name: name,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				if dep != "" {
+					ctn.Get(dep)
+				}
+				return name, nil
+			},
+			Close: func(obj interface{}) error {
+				time.Sleep(delay)
+				closed = append(closed, obj.(string))
+				return closeErr
+			},
+		}
+	}
+
+	b := &di.Builder{}
+	err := b.Add(
+		def("worker", "dao", errors.New("expected error"), 0),
+		def("dao", "db", nil, 0),
+		def("db", "", errors.New("expected error"), 0),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = ctn.Close(context.Background())
+	closeErr := &di.CloseError{}
+	if assert.ErrorAs(t, err, &closeErr) {
+		assert.Len(t, closeErr.Errs, 2)
+	}
+	assert.Equal(t, []string{"worker", "dao", "db"}, closed)
+
+	assert.NoError(t, ctn.Close(context.Background()))
+	assert.Len(t, closed, 3)
+
+	b = &di.Builder{}
+	err = b.Add(def("slow", "", nil, time.Second), def("next", "", nil, 0))
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err = b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = ctn.Close(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestContainer_Replace(t *testing.T) {
+	var closed []interface{}
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: "conf",
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return "v1", nil
+			},
+		},
+		di.Def{
+			Name: "lazy",
+			Lazy: true,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return "built", nil
+			},
+			Close: func(obj interface{}) error {
+				closed = append(closed, obj)
+				return nil
+			},
+		},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, ctn.Replace("conf", "v2"))
+	assert.Equal(t, "v2", ctn.Get("conf"))
+
+	assert.NoError(t, ctn.Replace("lazy", "replaced"))
+	assert.Equal(t, "replaced", ctn.Get("lazy"))
+	assert.Error(t, ctn.Replace("unknown", "v"))
+
+	assert.NoError(t, ctn.Close(context.Background()))
+	assert.Equal(t, []interface{}{"replaced"}, closed)
+}
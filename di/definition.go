@@ -1,6 +1,11 @@
 package di
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
 
 // DefsMap is a dependencies definitions map
 type DefsMap map[string]Def
@@ -14,6 +19,9 @@ type BuildFn func(ctn *Container) (interface{}, error)
 // CloseFn is a dependency close function
 type CloseFn func(obj interface{}) error
 
+// DecorateFn wraps a built dependency object
+type DecorateFn func(ctn *Container, obj interface{}) (interface{}, error)
+
 // Def is a dependency definition
 type Def struct {
 	// Name is a dependency name
@@ -22,6 +30,13 @@ type Def struct {
 	// Lazy is a flag. If true, Build will be executed only on Container.Get() call.
 	Lazy bool
 
+	// Scope is a dependency scope. Non-app scoped dependencies are built
+	// lazily in the Container.SubContainer() of the scope.
+	Scope Scope
+
+	// Tags are the names of the groups the dependency is included to, see Container.GetByTag()
+	Tags []string
+
 	// Validate validates dependency definition on add
 	Validate ValidateFn
 
@@ -31,22 +46,90 @@ type Def struct {
 	// Close finalizes dependency object
 	Close CloseFn
 
+	// HealthCheck checks if the built dependency object is healthy
+	HealthCheck HealthCheckFn
+
+	// HealthTimeout limits HealthCheck call, healthTimeoutDft if zero
+	HealthTimeout time.Duration
+
+	// NonCritical is a flag. If true, HealthCheck failure doesn't make the service unready.
+	NonCritical bool
+
+	// decorators wrap the built object in order of registration
+	decorators []DecorateFn
+
+	// raw is a built object before decoration, it is passed to Close and HealthCheck
+	raw   interface{}
 	obj   interface{}
+	err   error
 	built bool
+
+	// duration is a build duration including dependencies built during the build
+	duration time.Duration
 }
 
-// build builds dependency's object
-func (d *Def) build(ctn *Container) error {
-	if d.built {
-		return nil
-	}
+// build builds dependency's object and stores the build result.
+// Panic in Build function is recovered as the build error.
+func (d *Def) build(ctn *Container) {
 	d.built = true
+	start := time.Now()
+	defer func() {
+		d.duration = time.Since(start)
+	}()
 
 	if d.Build == nil {
-		return errors.New("[pagocore.di] definition `" + d.Name + "`: build function is not defined")
+		d.err = errors.New("[pagocore.di] definition `" + d.Name + "`: build function is not defined")
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(error); ok {
+				d.err = fmt.Errorf("[pagocore.di] definition `%s`: build failed: %w", d.Name, err)
+			} else {
+				d.err = fmt.Errorf("[pagocore.di] definition `%s`: build failed: %v", d.Name, r)
+			}
+		}
+	}()
+
+	d.obj, d.err = d.Build(ctn)
+	d.raw = d.obj
+	for _, decorate := range d.decorators {
+		if d.err != nil {
+			return
+		}
+		d.obj, d.err = decorate(ctn, d.obj)
+	}
+}
+
+// close finalizes dependency's object. Returns ctx error if ctx is done before Close returns.
+func (d *Def) close(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("[pagocore.di] definition `%s`: close skipped: %w", d.Name, ctx.Err())
 	}
 
-	var err error
-	d.obj, err = d.Build(ctn)
-	return err
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- d.Close(d.raw)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("[pagocore.di] definition `%s`: close failed: %w", d.Name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("[pagocore.di] definition `%s`: close timed out: %w", d.Name, ctx.Err())
+	}
+}
+
+// hasTag checks if the definition has the tag
+func (d *Def) hasTag(tag string) bool {
+	for _, t := range d.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,34 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDependencyCycle is returned if a dependency is required during its own build
+var ErrDependencyCycle = errors.New("[pagocore.di] dependency cycle detected")
+
+// newCycleError creates ErrDependencyCycle error with the chain of definitions names
+func newCycleError(chain []string) error {
+	return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(chain, " -> "))
+}
+
+// CloseError is an aggregated error of the Container.Close
+type CloseError struct {
+	Errs []error
+}
+
+// Error as a string
+func (e *CloseError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the list of close errors
+func (e *CloseError) Unwrap() []error {
+	return e.Errs
+}
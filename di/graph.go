@@ -0,0 +1,76 @@
+package di
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Graph is a dependency graph of the Container
+type Graph struct {
+	Nodes []*GraphNode `json:"nodes"`
+}
+
+// GraphNode is a dependency graph node
+type GraphNode struct {
+	Name  string `json:"name"`
+	Scope Scope  `json:"scope,omitempty"`
+	Lazy  bool   `json:"lazy"`
+	Built bool   `json:"built"`
+	Error string `json:"error,omitempty"`
+
+	// BuildDuration is a build duration including dependencies built during the build
+	BuildDuration time.Duration `json:"build_duration,omitempty"`
+
+	// Deps is a list of dependencies names the node got during its build
+	Deps []string `json:"deps,omitempty"`
+}
+
+// Graph returns the dependency graph in order of definitions registration.
+// Dependency edges are discovered during builds, so lazy definitions which are not built yet have no edges.
+func (c *Container) Graph() *Graph {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g := &Graph{
+		Nodes: make([]*GraphNode, len(c.ord)),
+	}
+	for i, name := range c.ord {
+		def := c.defs[name]
+		node := &GraphNode{
+			Name:  name,
+			Scope: def.Scope,
+			Lazy:  def.Lazy,
+			Built: def.built,
+			Deps:  append([]string(nil), c.deps[name]...),
+
+			BuildDuration: def.duration,
+		}
+		if def.err != nil {
+			node.Error = def.err.Error()
+		}
+		g.Nodes[i] = node
+	}
+	return g
+}
+
+// DOT returns the graph in Graphviz DOT format
+func (g *Graph) DOT() string {
+	sb := &strings.Builder{}
+	sb.WriteString("digraph di {\n")
+	for _, node := range g.Nodes {
+		attrs := []string{"label=" + strconv.Quote(node.Name)}
+		if !node.Built {
+			attrs = append(attrs, "style=dashed")
+		}
+		if node.Error != "" {
+			attrs = append(attrs, "color=red")
+		}
+		sb.WriteString("\t" + strconv.Quote(node.Name) + " [" + strings.Join(attrs, ", ") + "];\n")
+		for _, dep := range node.Deps {
+			sb.WriteString("\t" + strconv.Quote(node.Name) + " -> " + strconv.Quote(dep) + ";\n")
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,62 @@
+package di_test
+
+import (
+	"encoding/json"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestContainer_Graph(t *testing.T) {
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: "conf",
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return "conf", nil
+			},
+		},
+		di.Def{
+			Name: "db",
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return ctn.Get("conf"), nil
+			},
+		},
+		di.Def{
+			Name: "dao",
+			Lazy: true,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				time.Sleep(time.Millisecond)
+				return ctn.Get("db"), nil
+			},
+		},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	g := ctn.Graph()
+	if !assert.Len(t, g.Nodes, 3) {
+		return
+	}
+	assert.Equal(t, "conf", g.Nodes[0].Name)
+	assert.Equal(t, []string{"conf"}, g.Nodes[1].Deps)
+	assert.False(t, g.Nodes[2].Built)
+	assert.Empty(t, g.Nodes[2].Deps)
+	assert.Zero(t, g.Nodes[2].BuildDuration)
+
+	_ = ctn.Get("dao")
+	g = ctn.Graph()
+	assert.True(t, g.Nodes[2].Built)
+	assert.Equal(t, []string{"db"}, g.Nodes[2].Deps)
+	assert.GreaterOrEqual(t, g.Nodes[2].BuildDuration, time.Millisecond)
+
+	_, err = json.Marshal(g)
+	assert.NoError(t, err)
+	assert.Contains(t, g.DOT(), `"dao" -> "db";`)
+}
@@ -0,0 +1,98 @@
+package di
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// healthTimeoutDft is a default health check timeout
+const healthTimeoutDft = 3 * time.Second
+
+// Health check statuses
+const (
+	HealthStatusOk   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// HealthCheckFn checks if the built dependency object is healthy
+type HealthCheckFn func(ctx context.Context, obj interface{}) error
+
+// HealthResult is a result of the dependency health check
+type HealthResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Error    string `json:"error,omitempty"`
+	Latency  string `json:"latency"`
+}
+
+// Ok checks if the health check is passed
+func (r *HealthResult) Ok() bool {
+	return r.Status == HealthStatusOk
+}
+
+// CheckHealth runs health checks of built app-scoped dependencies concurrently.
+// Lazy dependencies which are not built yet are skipped.
+// Results are returned in order of dependencies build.
+func (c *Container) CheckHealth(ctx context.Context) []*HealthResult {
+	root := c.root()
+	var defs []Def
+	root.mu.Lock()
+	for _, name := range root.built {
+		def := root.defs[name]
+		if def.HealthCheck != nil {
+			defs = append(defs, def)
+		}
+	}
+	root.mu.Unlock()
+
+	results := make([]*HealthResult, len(defs))
+	wg := sync.WaitGroup{}
+	for i, def := range defs {
+		wg.Add(1)
+		go func(i int, def Def) {
+			defer wg.Done()
+			results[i] = def.checkHealth(ctx)
+		}(i, def)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// checkHealth runs dependency's health check with the timeout applied
+func (d *Def) checkHealth(ctx context.Context) *HealthResult {
+	timeout := d.HealthTimeout
+	if timeout <= 0 {
+		timeout = healthTimeoutDft
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res := &HealthResult{
+		Name:     d.Name,
+		Status:   HealthStatusOk,
+		Critical: !d.NonCritical,
+	}
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- d.HealthCheck(ctx, d.raw)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	res.Latency = time.Since(start).String()
+
+	if err != nil {
+		res.Status = HealthStatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
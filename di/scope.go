@@ -0,0 +1,64 @@
+package di
+
+import "context"
+
+// Scope is a dependency scope
+type Scope string
+
+// Scopes
+const (
+	// ScopeApp dependencies are built once per App's Container
+	ScopeApp Scope = ""
+
+	// ScopeRequest dependencies are built once per request's sub-container
+	ScopeRequest Scope = "request"
+)
+
+// SubContainer creates a child Container for the scope.
+// Dependencies of the scope are built lazily in the child Container,
+// others are taken from the parent. ctx is available to the Build functions via Container.Context().
+// Child Container must be closed when the scope ends.
+func (c *Container) SubContainer(ctx context.Context, scope Scope) *Container {
+	child := newContainer()
+	child.parent = c
+	child.scope = scope
+	child.ctx = ctx
+
+	root := c.root()
+	root.mu.Lock()
+	for _, name := range root.ord {
+		def := root.defs[name]
+		if def.Scope == scope {
+			def.Lazy = true
+			child.defs[name] = def
+			child.ord = append(child.ord, name)
+		}
+	}
+	root.mu.Unlock()
+	return child
+}
+
+// Scope returns Container's scope
+func (c *Container) Scope() Scope {
+	return c.scope
+}
+
+// Context returns Container's context. Returns context.Background() if none is set.
+func (c *Container) Context() context.Context {
+	if c.ctx == nil {
+		if c.parent != nil {
+			return c.parent.Context()
+		}
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// root returns the top-level Container
+func (c *Container) root() *Container {
+	root := c
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
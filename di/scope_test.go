@@ -0,0 +1,62 @@
+package di_test
+
+import (
+	"context"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type ctxKey string
+
+func TestContainer_SubContainer(t *testing.T) {
+	builds := 0
+	closed := 0
+
+	b := &di.Builder{}
+	err := b.Add(
+		di.Def{
+			Name: "app",
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return "app_val", nil
+			},
+		},
+		di.Def{
+			Name:  "req",
+			Scope: di.ScopeRequest,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				builds++
+				return ctn.Get("app").(string) + ":" + ctn.Context().Value(ctxKey("id")).(string), nil
+			},
+			Close: func(obj interface{}) error {
+				closed++
+				return nil
+			},
+		},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 0, builds)
+
+	_, err = ctn.SafeGet("req")
+	assert.Error(t, err)
+
+	for _, id := range []string{"r1", "r2"} {
+		reqCtn := ctn.SubContainer(context.WithValue(context.Background(), ctxKey("id"), id), di.ScopeRequest)
+		assert.Equal(t, di.ScopeRequest, reqCtn.Scope())
+		assert.True(t, reqCtn.Has("app"))
+		assert.Equal(t, "app_val", reqCtn.Get("app"))
+		assert.Equal(t, "app_val:"+id, reqCtn.Get("req"))
+		assert.Equal(t, "app_val:"+id, reqCtn.Get("req"))
+		assert.NoError(t, reqCtn.Close(context.Background()))
+	}
+
+	assert.Equal(t, 2, builds)
+	assert.Equal(t, 2, closed)
+}
@@ -0,0 +1,40 @@
+package di
+
+// GetByTag returns built dependencies with the tag in order of registration. Panics on error.
+func (c *Container) GetByTag(tag string) []interface{} {
+	objs, err := c.SafeGetByTag(tag)
+	if err != nil {
+		panic(err)
+	}
+	return objs
+}
+
+// SafeGetByTag returns built dependencies with the tag in order of registration.
+// Dependencies of the other scopes are skipped.
+func (c *Container) SafeGetByTag(tag string) ([]interface{}, error) {
+	var objs []interface{}
+	for _, name := range c.tagged(tag) {
+		obj, err := c.SafeGet(name)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// tagged returns names of the definitions with the tag available in the Container's scope
+func (c *Container) tagged(tag string) []string {
+	root := c.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	var names []string
+	for _, name := range root.ord {
+		def := root.defs[name]
+		if def.hasTag(tag) && (def.Scope == ScopeApp || def.Scope == c.scope) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
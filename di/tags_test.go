@@ -0,0 +1,46 @@
+package di_test
+
+import (
+	"context"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestContainer_GetByTag(t *testing.T) {
+	def := func(name string, scope di.Scope, tags ...string) di.Def {
+		return di.Def{
+			Name:  name,
+			Scope: scope,
+			Lazy:  true,
+			Tags:  tags,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return name, nil
+			},
+		}
+	}
+
+	b := &di.Builder{}
+	err := b.Add(
+		def("h1", di.ScopeApp, "handler"),
+		def("other", di.ScopeApp, "other"),
+		def("h2", di.ScopeRequest, "other", "handler"),
+		def("h3", di.ScopeApp, "handler"),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []interface{}{"h1", "h3"}, ctn.GetByTag("handler"))
+	assert.Empty(t, ctn.GetByTag("unknown"))
+
+	reqCtn := ctn.SubContainer(context.Background(), di.ScopeRequest)
+	assert.Equal(t, []string{"h1", "h2", "h3"}, di.GetByTag[string](reqCtn, "handler"))
+
+	_, err = di.SafeGetByTag[int](ctn, "handler")
+	assert.ErrorIs(t, err, di.ErrTypeMismatch)
+}
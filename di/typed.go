@@ -0,0 +1,95 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrTypeMismatch is returned if a dependency object has unexpected type
+var ErrTypeMismatch = errors.New("[pagocore.di] dependency type mismatch")
+
+// Get returns built dependency of type T. Panics on error.
+func Get[T any](ctn *Container, name string) T {
+	obj, err := SafeGet[T](ctn, name)
+	if err != nil {
+		panic(err)
+	}
+	return obj
+}
+
+// SafeGet returns built dependency of type T.
+// Returns ErrTypeMismatch error if the dependency object is not T.
+// Nil object is returned as a zero value of T.
+func SafeGet[T any](ctn *Container, name string) (T, error) {
+	var zero T
+	obj, err := ctn.SafeGet(name)
+	if err != nil {
+		return zero, err
+	}
+	if obj == nil {
+		return zero, nil
+	}
+	v, ok := obj.(T)
+	if !ok {
+		expected := reflect.TypeOf((*T)(nil)).Elem()
+		return zero, fmt.Errorf("%w: `%s` is %T, expected %s", ErrTypeMismatch, name, obj, expected)
+	}
+	return v, nil
+}
+
+// GetByTag returns built dependencies with the tag as T. Panics on error.
+func GetByTag[T any](ctn *Container, tag string) []T {
+	objs, err := SafeGetByTag[T](ctn, tag)
+	if err != nil {
+		panic(err)
+	}
+	return objs
+}
+
+// SafeGetByTag returns built dependencies with the tag as T.
+// Returns ErrTypeMismatch error if any of the dependencies objects is not T.
+func SafeGetByTag[T any](ctn *Container, tag string) ([]T, error) {
+	var objs []T
+	for _, name := range ctn.tagged(tag) {
+		obj, err := SafeGet[T](ctn, name)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// Key is a typed dependency name
+type Key[T any] string
+
+// NewKey creates a typed dependency name
+func NewKey[T any](name string) Key[T] {
+	return Key[T](name)
+}
+
+// Name returns dependency name
+func (k Key[T]) Name() string {
+	return string(k)
+}
+
+// Def returns a dependency definition with the typed build function
+func (k Key[T]) Def(build func(ctn *Container) (T, error)) Def {
+	return Def{
+		Name: k.Name(),
+		Build: func(ctn *Container) (interface{}, error) {
+			return build(ctn)
+		},
+	}
+}
+
+// Get returns built dependency. Panics on error.
+func (k Key[T]) Get(ctn *Container) T {
+	return Get[T](ctn, k.Name())
+}
+
+// SafeGet returns built dependency
+func (k Key[T]) SafeGet(ctn *Container) (T, error) {
+	return SafeGet[T](ctn, k.Name())
+}
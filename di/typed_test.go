@@ -0,0 +1,51 @@
+package di_test
+
+import (
+	"fmt"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testService struct {
+	Name string
+}
+
+func TestKey(t *testing.T) {
+	key := di.NewKey[*testService]("service")
+	nilKey := di.NewKey[fmt.Stringer]("nil")
+
+	b := &di.Builder{}
+	err := b.Add(
+		key.Def(func(ctn *di.Container) (*testService, error) {
+			return &testService{Name: "test"}, nil
+		}),
+		nilKey.Def(func(ctn *di.Container) (fmt.Stringer, error) {
+			return nil, nil
+		}),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "service", key.Name())
+	assert.Equal(t, "test", key.Get(ctn).Name)
+	assert.Equal(t, "test", di.Get[*testService](ctn, "service").Name)
+
+	s, err := nilKey.SafeGet(ctn)
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	_, err = di.SafeGet[string](ctn, "service")
+	assert.ErrorIs(t, err, di.ErrTypeMismatch)
+	assert.Panics(t, func() {
+		di.Get[fmt.Stringer](ctn, "service")
+	})
+
+	_, err = di.SafeGet[string](ctn, "unknown")
+	assert.Error(t, err)
+}
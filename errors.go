@@ -5,15 +5,32 @@ import (
 	"net/http"
 )
 
+// Errors reasons are stable machine-readable error codes, independent of the HTTP status
+const (
+	ReasonBadRequest       = "bad_request"
+	ReasonMalformedInput   = "malformed_input"
+	ReasonValidation       = "validation_failed"
+	ReasonUserBlocked      = "user_blocked"
+	ReasonPassFailed       = "password_failed"
+	ReasonRoleNotAllowed   = "role_not_allowed"
+	ReasonTokenInvalid     = "token_invalid"
+	ReasonTokenExpired     = "token_expired"
+	ReasonTokenUnsupported = "token_unsupported"
+	ReasonNotFound         = "not_found"
+)
+
+// msgValidation is the message of validation errors
+const msgValidation = "invalid input"
+
 // Errors
 var (
-	ErrUserBlocked      = NewError(http.StatusForbidden, "requested user is blocked")
-	ErrPassFailed       = NewError(http.StatusUnauthorized, "password is invalid")
-	ErrRoleNotAllowed   = NewError(http.StatusForbidden, "unexpected user role")
-	ErrTokenInvalid     = NewError(http.StatusUnauthorized, "token is invalid")
-	ErrTokenExpired     = NewError(http.StatusUnauthorized, "token is expired")
-	ErrTokenUnsupported = NewError(http.StatusUnprocessableEntity, "unsupported sign method")
-	ErrNotFound         = NewError(http.StatusNotFound, "not found")
+	ErrUserBlocked      = NewErrorReason(http.StatusForbidden, ReasonUserBlocked, "requested user is blocked")
+	ErrPassFailed       = NewErrorReason(http.StatusUnauthorized, ReasonPassFailed, "password is invalid")
+	ErrRoleNotAllowed   = NewErrorReason(http.StatusForbidden, ReasonRoleNotAllowed, "unexpected user role")
+	ErrTokenInvalid     = NewErrorReason(http.StatusUnauthorized, ReasonTokenInvalid, "token is invalid")
+	ErrTokenExpired     = NewErrorReason(http.StatusUnauthorized, ReasonTokenExpired, "token is expired")
+	ErrTokenUnsupported = NewErrorReason(http.StatusUnprocessableEntity, ReasonTokenUnsupported, "unsupported sign method")
+	ErrNotFound         = NewErrorReason(http.StatusNotFound, ReasonNotFound, "not found")
 )
 
 // NewError creates a new Error instance
@@ -29,14 +46,79 @@ func NewError(code int, messages ...interface{}) *Error {
 	}
 }
 
+// NewErrorReason creates a new Error instance with the machine-readable reason
+func NewErrorReason(code int, reason string, messages ...interface{}) *Error {
+	e := NewError(code, messages...)
+	e.Reason = reason
+	return e
+}
+
+// NewBadRequestError creates a new 400 Error instance
+func NewBadRequestError(messages ...interface{}) *Error {
+	return NewErrorReason(http.StatusBadRequest, ReasonBadRequest, messages...)
+}
+
+// NewValidationError creates a new 422 Error instance with the invalid fields errors
+func NewValidationError(fields ...*FieldError) *Error {
+	e := NewErrorReason(http.StatusUnprocessableEntity, ReasonValidation, msgValidation)
+	e.Fields = fields
+	return e
+}
+
 // Error defines the response error
 type Error struct {
 	Code      int    `json:"code" example:"403"`
 	Message   string `json:"message" example:"Access denied"`
 	Localized string `json:"localized,omitempty" example:"Доступ запрещен"`
+	RequestID string `json:"request_id,omitempty" example:"0f6b7f62-8e0a-4a53-9a50-1b7d5c6c1f3e"`
+
+	// Reason is a stable machine-readable error code, e.g. ReasonValidation
+	Reason string `json:"reason,omitempty" example:"validation_failed"`
+
+	// Fields are the invalid input fields errors
+	Fields []*FieldError `json:"fields,omitempty"`
 }
 
 // Error as a string
 func (e *Error) Error() string {
 	return e.Message
 }
+
+// WithField adds the invalid field error and returns the Error.
+// Do not use it on shared errors like ErrNotFound, use NewValidationError instead.
+func (e *Error) WithField(field, rule, message string) *Error {
+	e.Fields = append(e.Fields, NewFieldRuleError(field, rule, message))
+	return e
+}
+
+// FieldError defines the invalid input field error
+type FieldError struct {
+	// Field is the input field path, e.g. "address.city" or "items[0].name"
+	Field string `json:"field" example:"email"`
+
+	// Rule is the failed validation rule, e.g. "required"
+	Rule string `json:"rule,omitempty" example:"required"`
+
+	Message   string `json:"message" example:"is required"`
+	Localized string `json:"localized,omitempty" example:"обязательное поле"`
+}
+
+// NewFieldError creates a new FieldError instance
+func NewFieldError(field, message string) *FieldError {
+	return &FieldError{
+		Field:   field,
+		Message: message,
+	}
+}
+
+// NewFieldRuleError creates a new FieldError instance with the failed rule
+func NewFieldRuleError(field, rule, message string) *FieldError {
+	e := NewFieldError(field, message)
+	e.Rule = rule
+	return e
+}
+
+// Error as a string
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
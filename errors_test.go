@@ -1,6 +1,7 @@
 package pagocore_test
 
 import (
+	"encoding/json"
 	"github.com/proactiongo/pagocore"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -30,3 +31,41 @@ func TestError_Error(t *testing.T) {
 		assert.Equal(t, text, err.Error())
 	}
 }
+
+func TestNewErrorReason(t *testing.T) {
+	err := pagocore.NewErrorReason(http.StatusConflict, "duplicate", "already exists")
+	assert.Equal(t, http.StatusConflict, err.Code)
+	assert.Equal(t, "duplicate", err.Reason)
+	assert.Equal(t, "already exists", err.Message)
+
+	err = pagocore.NewBadRequestError()
+	assert.Equal(t, http.StatusBadRequest, err.Code)
+	assert.Equal(t, pagocore.ReasonBadRequest, err.Reason)
+	assert.Equal(t, "Bad Request", err.Message)
+
+	assert.Equal(t, pagocore.ReasonNotFound, pagocore.ErrNotFound.Reason)
+}
+
+func TestNewValidationError(t *testing.T) {
+	err := pagocore.NewValidationError(pagocore.NewFieldError("name", "is reserved")).
+		WithField("email", "required", "is required")
+	assert.Equal(t, http.StatusUnprocessableEntity, err.Code)
+	assert.Equal(t, pagocore.ReasonValidation, err.Reason)
+	if assert.Len(t, err.Fields, 2) {
+		assert.Equal(t, "name: is reserved", err.Fields[0].Error())
+		assert.Equal(t, "", err.Fields[0].Rule)
+		assert.Equal(t, "email", err.Fields[1].Field)
+		assert.Equal(t, "required", err.Fields[1].Rule)
+	}
+}
+
+func TestError_JSON(t *testing.T) {
+	data, err := json.Marshal(pagocore.NewError(http.StatusForbidden, "Access denied"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"code":403,"message":"Access denied"}`, string(data))
+
+	data, err = json.Marshal(pagocore.NewValidationError().WithField("email", "email", "must be a valid email"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"code":422,"message":"invalid input","reason":"validation_failed",`+
+		`"fields":[{"field":"email","rule":"email","message":"must be a valid email"}]}`, string(data))
+}
@@ -39,7 +39,7 @@ func (h *ContextHandler) GetContainer() *di.Container {
 
 // GetI18nSource returns i18n texts source from the container
 func (h *ContextHandler) GetI18nSource() *i18n.TextsSource {
-	return h.GetContainer().Get("pa_i18n").(*i18n.TextsSource)
+	return di.Get[*i18n.TextsSource](h.GetContainer(), "pa_i18n")
 }
 
 // GetAccessClaims returns AccessTokenClaims from the current gin context
@@ -76,14 +76,16 @@ func (h *ContextHandler) ExtractBearerToken() (string, error) {
 	return utils.ExtractBearerToken(h.Request)
 }
 
-// Err sends error as response
+// Err sends error as response, *pagocore.FieldError is sent as the validation error
 func (h *ContextHandler) Err(err error) {
 	status := http.StatusInternalServerError
-	e, ok := err.(*pagocore.Error)
-	if ok {
+	switch e := err.(type) {
+	case *pagocore.Error:
 		if e.Code >= 400 && e.Code <= 599 {
 			status = e.Code
 		}
+	case *pagocore.FieldError:
+		status = http.StatusUnprocessableEntity
 	}
 	h.ErrWithStatus(err, status)
 }
@@ -93,15 +95,51 @@ func (h *ContextHandler) ErrS(msg string, status int) {
 	h.Err(pagocore.NewError(status, msg))
 }
 
-// ErrWithStatus sends error as response with custom status
+// ErrWithStatus sends error as response with custom status.
+// The error is copied, so shared errors like pagocore.ErrNotFound are never modified.
 func (h *ContextHandler) ErrWithStatus(err error, status int) {
 	e, ok := err.(*pagocore.Error)
 	if !ok {
-		e = pagocore.NewError(status, err.Error())
+		if fe, isField := err.(*pagocore.FieldError); isField {
+			e = pagocore.NewValidationError(fe)
+			e.Code = status
+		} else {
+			e = pagocore.NewError(status, err.Error())
+		}
+	}
+	resp := *e
+	resp.Localized = h.GetI18nSource().T(e.Error(), h.GetI18nLang(), nil)
+	resp.RequestID = h.GetRequestID()
+	if len(e.Fields) > 0 {
+		resp.Fields = make([]*pagocore.FieldError, len(e.Fields))
+		for i, f := range e.Fields {
+			field := *f
+			field.Localized = h.GetI18nSource().T(f.Message, h.GetI18nLang(), nil)
+			resp.Fields[i] = &field
+		}
 	}
-	e.Localized = h.GetI18nSource().T(e.Error(), h.GetI18nLang(), nil)
 	h.JSON(
 		status,
-		e,
+		&resp,
 	)
 }
+
+// GetRequestID returns the request ID set by Middlewares.RequestID, or empty string
+func (h *ContextHandler) GetRequestID() string {
+	return h.GetString(KeyRequestID)
+}
+
+// GetCorrelationID returns the correlation ID set by Middlewares.RequestID, or empty string
+func (h *ContextHandler) GetCorrelationID() string {
+	return h.GetString(KeyCorrelationID)
+}
+
+// Log returns the logger entry with the request and correlation IDs.
+// Trace and span IDs are added by pagocore.LogDftFieldsHook from the request context.
+func (h *ContextHandler) Log() *log.Entry {
+	return log.WithContext(h.Request.Context()).WithFields(log.Fields{
+		pagocore.LogFieldType:          pagocore.LogTypeApp,
+		pagocore.LogFieldRequestID:     h.GetRequestID(),
+		pagocore.LogFieldCorrelationID: h.GetCorrelationID(),
+	})
+}
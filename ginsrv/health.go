@@ -0,0 +1,62 @@
+package ginsrv
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"net/http"
+)
+
+// Health report statuses
+const (
+	HealthStatusOk       = "ok"
+	HealthStatusDegraded = "degraded"
+	HealthStatusFail     = "fail"
+)
+
+// HealthReport is a service health report
+type HealthReport struct {
+	Status  string                `json:"status"`
+	Service *pagocore.ServiceInfo `json:"service"`
+	Checks  []*di.HealthResult    `json:"checks,omitempty"`
+}
+
+// RegisterHealthRoutes mounts liveness (/healthz) and readiness (/readyz) endpoints
+func RegisterHealthRoutes(router gin.IRoutes) {
+	router.GET("/healthz", LivenessHandler)
+	router.GET("/readyz", ReadinessHandler)
+}
+
+// LivenessHandler responds with the service info if the service is alive
+func LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, &HealthReport{
+		Status:  HealthStatusOk,
+		Service: pagocore.GetServiceInfo(),
+	})
+}
+
+// ReadinessHandler runs health checks of the DI container dependencies.
+// Responds with 503 if any critical check fails.
+func ReadinessHandler(c *gin.Context) {
+	ctx := NewContextHandler(c)
+	report := &HealthReport{
+		Status:  HealthStatusOk,
+		Service: pagocore.GetServiceInfo(),
+		Checks:  ctx.GetContainer().CheckHealth(c.Request.Context()),
+	}
+
+	status := http.StatusOK
+	for _, check := range report.Checks {
+		if check.Ok() {
+			continue
+		}
+		if check.Critical {
+			report.Status = HealthStatusFail
+			status = http.StatusServiceUnavailable
+			break
+		}
+		report.Status = HealthStatusDegraded
+	}
+
+	c.JSON(status, report)
+}
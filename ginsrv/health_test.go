@@ -0,0 +1,80 @@
+package ginsrv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	check := func(err error) di.HealthCheckFn {
+		return func(ctx context.Context, obj interface{}) error {
+			return err
+		}
+	}
+	def := func(name string, fn di.HealthCheckFn, nonCritical bool) di.Def {
+		return di.Def{
+			Name: name,
+			Build: func(ctn *di.Container) (interface{}, error) {
+				return name, nil
+			},
+			HealthCheck: fn,
+			NonCritical: nonCritical,
+		}
+	}
+	slow := func(ctx context.Context, obj interface{}) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	cases := []struct {
+		defs   []di.Def
+		status int
+		report string
+	}{
+		{[]di.Def{def("d1", check(nil), false), def("d2", nil, false)}, http.StatusOK, HealthStatusOk},
+		{[]di.Def{def("d1", check(nil), false), def("d2", check(errors.New("expected error")), true)}, http.StatusOK, HealthStatusDegraded},
+		{[]di.Def{def("d1", check(errors.New("expected error")), false), def("d2", check(nil), true)}, http.StatusServiceUnavailable, HealthStatusFail},
+		{[]di.Def{{Name: "d1", Build: def("d1", nil, false).Build, HealthCheck: slow, HealthTimeout: 10 * time.Millisecond}}, http.StatusServiceUnavailable, HealthStatusFail},
+	}
+
+	for _, tc := range cases {
+		b := &di.Builder{}
+		if !assert.NoError(t, b.Add(tc.defs...)) {
+			return
+		}
+		ctn, err := b.Build()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		router := GetDefaultRouter()
+		router.Use(M().SetDIContainer(ctn))
+		RegisterHealthRoutes(router.Group("/api/v1"))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/readyz", nil))
+		assert.Equal(t, tc.status, w.Code)
+
+		report := &HealthReport{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), report))
+		assert.Equal(t, tc.report, report.Status)
+		assert.NotNil(t, report.Service)
+	}
+}
+
+func TestLivenessHandler(t *testing.T) {
+	router := gin.New()
+	RegisterHealthRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
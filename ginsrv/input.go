@@ -0,0 +1,282 @@
+package ginsrv
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/proactiongo/pagocore"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// msgInputMalformed is a message of the input binding error
+const msgInputMalformed = "malformed input"
+
+// msgInputUnsupportedType is a message of the input body with unsupported content type error
+const msgInputUnsupportedType = "unsupported content type"
+
+// inputFieldTags are the struct tags to get the input field name from, in order of priority
+var inputFieldTags = []string{"json", "form", "uri"}
+
+// validationMessages are messages of the validator tags, {param} is replaced with the tag param
+var validationMessages = map[string]string{
+	"required": "is required",
+	"email":    "must be a valid email",
+	"url":      "must be a valid URL",
+	"uuid":     "must be a valid UUID",
+	"min":      "must be at least {param}",
+	"max":      "must be at most {param}",
+	"len":      "must have length {param}",
+	"gt":       "must be greater than {param}",
+	"gte":      "must be at least {param}",
+	"lt":       "must be less than {param}",
+	"lte":      "must be at most {param}",
+	"oneof":    "must be one of: {param}",
+}
+
+// BindInput binds URI params, query and JSON or form body to the target according to its tags,
+// calls target Filter(), validates `binding` tags, then calls target Validate().
+// URI params, query and form values are bound only to the fields with explicit `uri` and `form` tags.
+// On failure responds with 400 if the input is malformed, 415 if the body is neither JSON nor form,
+// or with 422 and the localized fields errors if it is invalid, and returns the responded error.
+//
+// Validate() may return *pagocore.Error to respond as is, *pagocore.FieldError for a single field,
+// or any other error to respond with 422 and its message.
+func (h *ContextHandler) BindInput(target pagocore.Input) error {
+	err := h.bindInput(target)
+	if err != nil {
+		h.Err(err)
+		return err
+	}
+
+	target.Filter()
+
+	err = validateInput(target)
+	if err != nil {
+		h.Err(err)
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		e := inputValidateError(err)
+		h.Err(e)
+		return e
+	}
+
+	return nil
+}
+
+// bindInput binds request data to the target.
+// Each gin binding validates the whole target, so the validation errors are skipped until the target is filtered.
+func (h *ContextHandler) bindInput(target interface{}) error {
+	malformed := pagocore.NewErrorReason(http.StatusBadRequest, pagocore.ReasonMalformedInput, msgInputMalformed)
+
+	if len(h.Params) > 0 {
+		params := make(map[string][]string, len(h.Params))
+		for _, p := range h.Params {
+			params[p.Key] = []string{p.Value}
+		}
+		params = filterInputValues(params, inputTagKeys(target, "uri"))
+		err := skipValidationErr(binding.Uri.BindUri(params, target))
+		if err != nil {
+			return malformed
+		}
+	}
+
+	formKeys := inputTagKeys(target, "form")
+	err := skipValidationErr(binding.Query.Bind(filterInputRequest(h.Request, formKeys), target))
+	if err != nil {
+		return malformed
+	}
+
+	if h.Request.Body != nil && h.Request.ContentLength != 0 {
+		switch h.ContentType() {
+		case binding.MIMEJSON:
+			err = h.ShouldBindWith(target, binding.JSON)
+		case binding.MIMEPOSTForm:
+			err = h.Request.ParseForm()
+			if err == nil {
+				err = binding.Form.Bind(filterInputRequest(h.Request, formKeys), target)
+			}
+		case binding.MIMEMultipartPOSTForm:
+			_, err = h.MultipartForm()
+			if err == nil {
+				err = binding.FormMultipart.Bind(filterInputRequest(h.Request, formKeys), target)
+			}
+		default:
+			return pagocore.NewErrorReason(http.StatusUnsupportedMediaType, pagocore.ReasonMalformedInput, msgInputUnsupportedType)
+		}
+		err = skipValidationErr(err)
+		if err != nil {
+			return malformed
+		}
+	}
+	return nil
+}
+
+// filterInputRequest returns a shallow copy of the request with the query and parsed form values of the keys only.
+// The form must be parsed before, so the binding does not read the body again.
+func filterInputRequest(req *http.Request, keys map[string]bool) *http.Request {
+	r := *req
+	u := *req.URL
+	u.RawQuery = url.Values(filterInputValues(req.URL.Query(), keys)).Encode()
+	r.URL = &u
+	r.Form = filterInputValues(req.Form, keys)
+	r.PostForm = filterInputValues(req.PostForm, keys)
+	if req.MultipartForm != nil {
+		r.MultipartForm = &multipart.Form{
+			Value: filterInputValues(req.MultipartForm.Value, keys),
+			File:  filterInputValues(req.MultipartForm.File, keys),
+		}
+	}
+	return &r
+}
+
+// filterInputValues returns the values of the keys only, nil if values are nil
+func filterInputValues[T any](values map[string]T, keys map[string]bool) map[string]T {
+	if values == nil {
+		return nil
+	}
+	filtered := make(map[string]T, len(keys))
+	for k, v := range values {
+		if keys[k] {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+// inputTagKeys returns the input keys named by the tag of the target fields, including nested structs.
+// gin binds the fields without the tag by their Go names, so the values of other keys must not be passed to it.
+func inputTagKeys(target interface{}, tag string) map[string]bool {
+	keys := make(map[string]bool)
+	visited := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || visited[t] {
+			return
+		}
+		visited[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" && !sf.Anonymous {
+				continue
+			}
+			name := strings.Split(sf.Tag.Get(tag), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name != "" {
+				keys[name] = true
+			}
+			walk(sf.Type)
+		}
+	}
+	walk(reflect.TypeOf(target))
+	return keys
+}
+
+// validateInput validates binding tags of the target
+func validateInput(target interface{}) error {
+	if binding.Validator == nil {
+		return nil
+	}
+	err := binding.Validator.ValidateStruct(target)
+	if err != nil {
+		return inputValidationErrors(target, err)
+	}
+	return nil
+}
+
+// skipValidationErr returns nil for validator errors
+func skipValidationErr(err error) error {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		return nil
+	}
+	return err
+}
+
+// inputValidationErrors converts the validator errors to pagocore.Error with fields errors
+func inputValidationErrors(target interface{}, err error) *pagocore.Error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return pagocore.NewErrorReason(http.StatusBadRequest, pagocore.ReasonMalformedInput, msgInputMalformed)
+	}
+
+	e := pagocore.NewValidationError()
+	t := reflect.TypeOf(target)
+	for _, fe := range verrs {
+		msg, ok := validationMessages[fe.Tag()]
+		if !ok {
+			msg = "is invalid"
+		}
+		msg = strings.ReplaceAll(msg, "{param}", fe.Param())
+		e.WithField(inputFieldName(t, fe.StructNamespace()), fe.Tag(), msg)
+	}
+	return e
+}
+
+// inputValidateError converts Input.Validate error to pagocore.Error
+func inputValidateError(err error) *pagocore.Error {
+	var e *pagocore.Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var fe *pagocore.FieldError
+	if errors.As(err, &fe) {
+		return pagocore.NewValidationError(fe)
+	}
+	return pagocore.NewErrorReason(http.StatusUnprocessableEntity, pagocore.ReasonValidation, err.Error())
+}
+
+// inputFieldName returns the input name of the field by its struct namespace, e. g. "Input.Address.City" -> "address.city"
+func inputFieldName(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 {
+		parts = parts[1:]
+	}
+
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+
+		// slice and map items are formatted as Field[0] in the namespace
+		fieldName, index := part, ""
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			fieldName, index = part[:i], part[i:]
+		}
+
+		name := fieldName
+		if t != nil && t.Kind() == reflect.Struct {
+			if sf, ok := t.FieldByName(fieldName); ok {
+				name = structFieldInputName(sf)
+				t = sf.Type
+			} else {
+				t = nil
+			}
+		}
+		names = append(names, name+index)
+	}
+	return strings.Join(names, ".")
+}
+
+// structFieldInputName returns the field name from its input tags or the field name itself
+func structFieldInputName(sf reflect.StructField) string {
+	for _, tag := range inputFieldTags {
+		name := strings.Split(sf.Tag.Get(tag), ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
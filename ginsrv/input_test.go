@@ -0,0 +1,155 @@
+package ginsrv
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/proactiongo/pagocore/i18n"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testInput struct {
+	ID      string `uri:"id" binding:"required"`
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	Email   string `json:"email" binding:"required,email"`
+	Name    string `json:"name"`
+	IsAdmin bool   `json:"is_admin"`
+	Address struct {
+		City string `json:"city" binding:"required"`
+	} `json:"address"`
+}
+
+func (i *testInput) Filter() {
+	i.Name = strings.TrimSpace(i.Name)
+	i.Address.City = strings.TrimSpace(i.Address.City)
+}
+
+func (i *testInput) Validate() error {
+	if i.Name == "admin" {
+		return pagocore.NewFieldError("name", "is reserved")
+	}
+	return nil
+}
+
+func TestContextHandler_BindInput(t *testing.T) {
+	b := &di.Builder{}
+	err := b.Add(di.Def{
+		Name: "pa_i18n",
+		Build: func(ctn *di.Container) (interface{}, error) {
+			return &i18n.TextsSource{
+				DefaultLang: "en",
+				Translations: i18n.Translations{
+					"is required": {"ru": {Text: "обязательное поле"}},
+				},
+			}, nil
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var bound *testInput
+	router := GetDefaultRouter()
+	router.Use(M().SetDIContainer(ctn))
+	router.PUT("/items/:id", func(c *gin.Context) {
+		ctx := NewContextHandler(c)
+		in := &testInput{}
+		if ctx.BindInput(in) != nil {
+			return
+		}
+		bound = in
+		c.Status(http.StatusOK)
+	})
+
+	request := func(url, contentType, body string) (int, *pagocore.Error) {
+		req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept-Language", "ru")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code == http.StatusOK {
+			return w.Code, nil
+		}
+		e := &pagocore.Error{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), e))
+		return w.Code, e
+	}
+
+	code, _ := request("/items/42?page=2", "application/json", `{"email":"a@b.c","name":" bob ","address":{"city":"Paris"}}`)
+	if assert.Equal(t, http.StatusOK, code) && assert.NotNil(t, bound) {
+		assert.Equal(t, "42", bound.ID)
+		assert.Equal(t, 2, bound.Page)
+		assert.Equal(t, "bob", bound.Name)
+		assert.Equal(t, "Paris", bound.Address.City)
+	}
+
+	bound = nil
+	code, _ = request("/items/42?page=2&IsAdmin=true&ID=1", "application/json", `{"email":"a@b.c","address":{"city":"Paris"}}`)
+	if assert.Equal(t, http.StatusOK, code) && assert.NotNil(t, bound) {
+		assert.False(t, bound.IsAdmin, "fields without form tag must not be bound from query")
+		assert.Equal(t, "42", bound.ID)
+		assert.Equal(t, 2, bound.Page)
+	}
+
+	code, _ = request("/items/42", "application/x-www-form-urlencoded", "page=3")
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, e := request("/items/42", "application/x-www-form-urlencoded", "page=-1&IsAdmin=true")
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	pageBound := false
+	for _, f := range e.Fields {
+		pageBound = pageBound || f.Field == "page"
+	}
+	assert.True(t, pageBound, "form tagged fields must be bound from form body")
+
+	code, e = request("/items/42", "application/json", `{"email":`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, pagocore.ReasonMalformedInput, e.Reason)
+	assert.Empty(t, e.Fields)
+
+	for _, contentType := range []string{"text/plain", ""} {
+		code, e = request("/items/42", contentType, `{"email":"a@b.c","address":{"city":"Paris"}}`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, code, "body must not be ignored, content type %q", contentType)
+		assert.Equal(t, pagocore.ReasonMalformedInput, e.Reason)
+	}
+
+	code, _ = request("/items/42?page=abc", "application/json", `{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, e = request("/items/42?page=-1", "application/json", `{"email":"invalid"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, pagocore.ReasonValidation, e.Reason)
+	if assert.Len(t, e.Fields, 3) {
+		fields := map[string]*pagocore.FieldError{}
+		for _, f := range e.Fields {
+			fields[f.Field] = f
+		}
+		assert.Equal(t, "must be at least 1", fields["page"].Message)
+		assert.Equal(t, "must be a valid email", fields["email"].Message)
+		assert.Equal(t, "is required", fields["address.city"].Message)
+		assert.Equal(t, "required", fields["address.city"].Rule)
+		assert.Equal(t, "обязательное поле", fields["address.city"].Localized)
+	}
+
+	code, e = request("/items/42", "application/json", `{"email":"a@b.c","address":{"city":"   "}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code, "binding tags must be validated after Filter")
+	if assert.Len(t, e.Fields, 1) {
+		assert.Equal(t, "address.city", e.Fields[0].Field)
+	}
+
+	code, e = request("/items/42", "application/json", `{"email":"a@b.c","name":"admin","address":{"city":"Paris"}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	if assert.Len(t, e.Fields, 1) {
+		assert.Equal(t, "name", e.Fields[0].Field)
+		assert.Equal(t, "is reserved", e.Fields[0].Message)
+	}
+}
@@ -2,10 +2,12 @@ package ginsrv
 
 import (
 	"bytes"
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/proactiongo/pagocore"
 	"github.com/proactiongo/pagocore/di"
 	"github.com/proactiongo/pagocore/i18n"
+	"github.com/proactiongo/pagocore/metrics"
 	"github.com/proactiongo/pagocore/tokens"
 	log "github.com/sirupsen/logrus"
 	"io"
@@ -41,10 +43,18 @@ var middlewares *Middlewares
 type Middlewares struct {
 }
 
-// SetDIContainer is a middleware to set app.App instance to the context
+// SetDIContainer is a middleware to set request-scoped sub-container of ctn to the context.
+// The sub-container context is the request context, so it is set after RequestID and Tracing middlewares.
+// The sub-container is closed when the request ends.
 func (m *Middlewares) SetDIContainer(ctn *di.Container) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Set(KeyDIContainer, ctn)
+		reqCtn := ctn.SubContainer(c.Request.Context(), di.ScopeRequest)
+		defer func() {
+			_ = reqCtn.Close(context.Background())
+		}()
+
+		c.Set(KeyDIContainer, reqCtn)
+		c.Next()
 	}
 }
 
@@ -207,6 +217,22 @@ func (m *Middlewares) InitI18nLang() gin.HandlerFunc {
 	}
 }
 
+// Metrics is a middleware to record count, latency and in-flight gauge of the requests
+// labelled by the route path, method and status, see metrics package
+func (m *Middlewares) Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := metrics.HTTPRequestStarted(c.FullPath(), c.Request.Method)
+		defer func() {
+			if r := recover(); r != nil {
+				done(http.StatusInternalServerError)
+				panic(r)
+			}
+			done(c.Writer.Status())
+		}()
+		c.Next()
+	}
+}
+
 // LogBody is a middleware to write response body to the log
 func (m *Middlewares) LogBody() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -214,10 +240,12 @@ func (m *Middlewares) LogBody() gin.HandlerFunc {
 		ctx.Writer = writer
 		ctx.Next()
 		logger := log.WithFields(log.Fields{
-			pagocore.LogFieldType:   pagocore.LogTypeHTTPIO,
-			pagocore.LogFieldStatus: ctx.Writer.Status(),
-			pagocore.LogFieldPath:   ctx.FullPath(),
-			pagocore.LogFieldMethod: ctx.Request.Method,
+			pagocore.LogFieldType:          pagocore.LogTypeHTTPIO,
+			pagocore.LogFieldStatus:        ctx.Writer.Status(),
+			pagocore.LogFieldPath:          ctx.FullPath(),
+			pagocore.LogFieldMethod:        ctx.Request.Method,
+			pagocore.LogFieldRequestID:     ctx.GetString(KeyRequestID),
+			pagocore.LogFieldCorrelationID: ctx.GetString(KeyCorrelationID),
 		})
 		if ctx.Writer.Status() >= 500 {
 			logger.Error(writer.body.String())
@@ -266,6 +294,9 @@ func (m *Middlewares) LogFormatter(param gin.LogFormatterParams) string {
 		data[pagocore.LogFieldLevel] = "info"
 	}
 
+	data[pagocore.LogFieldRequestID], _ = param.Keys[KeyRequestID].(string)
+	data[pagocore.LogFieldCorrelationID], _ = param.Keys[KeyCorrelationID].(string)
+
 	data[pagocore.LogFieldClientAppVersion] = param.Request.Header.Get("client_app_version")
 	data[pagocore.LogFieldClientAppPlatform] = param.Request.Header.Get("client_app_platform")
 
@@ -0,0 +1,26 @@
+package ginsrv
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore/metrics"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewares_Metrics(t *testing.T) {
+	router := gin.New()
+	router.Use(M().Metrics())
+	router.GET("/metrics_test/:id", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	})
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics_test/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics_test/2", nil))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `http_requests_total{method="GET",path="/metrics_test/:id",status="202"} 2`)
+}
@@ -0,0 +1,96 @@
+package ginsrv
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore/utils"
+)
+
+const (
+	// HeaderRequestID is a request ID header, the ID is generated if the header is missing or invalid
+	HeaderRequestID = "X-Request-ID"
+
+	// HeaderCorrelationID is an upstream correlation ID header, the request ID is used if it is missing
+	HeaderCorrelationID = "X-Correlation-ID"
+
+	// KeyRequestID is a context key for the request ID
+	KeyRequestID = "PARequestID"
+
+	// KeyCorrelationID is a context key for the correlation ID
+	KeyCorrelationID = "PACorrelationID"
+)
+
+// requestIDMaxLen limits the length of the request and correlation IDs accepted from headers
+const requestIDMaxLen = 128
+
+// requestIDsCtxKey is a context.Context key for the request IDs
+type requestIDsCtxKey struct{}
+
+// requestIDs are the request and correlation IDs stored in context.Context
+type requestIDs struct {
+	requestID     string
+	correlationID string
+}
+
+// RequestID is a middleware to read or generate the request ID and to accept the upstream correlation ID.
+// The IDs are stored in the gin context and in the request context.Context and echoed in the response headers.
+func (m *Middlewares) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !isValidRequestID(requestID) {
+			requestID = utils.GenerateUUID()
+		}
+		correlationID := c.GetHeader(HeaderCorrelationID)
+		if !isValidRequestID(correlationID) {
+			correlationID = requestID
+		}
+
+		c.Set(KeyRequestID, requestID)
+		c.Set(KeyCorrelationID, correlationID)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), requestID, correlationID))
+		c.Header(HeaderRequestID, requestID)
+		c.Header(HeaderCorrelationID, correlationID)
+
+		c.Next()
+	}
+}
+
+// WithRequestID returns a copy of ctx with the request and correlation IDs,
+// use it to propagate the IDs to background tasks and outgoing requests
+func WithRequestID(ctx context.Context, requestID, correlationID string) context.Context {
+	return context.WithValue(ctx, requestIDsCtxKey{}, &requestIDs{
+		requestID:     requestID,
+		correlationID: correlationID,
+	})
+}
+
+// RequestIDFromContext returns the request ID from ctx, or empty string if it is not set
+func RequestIDFromContext(ctx context.Context) string {
+	ids, ok := ctx.Value(requestIDsCtxKey{}).(*requestIDs)
+	if !ok {
+		return ""
+	}
+	return ids.requestID
+}
+
+// CorrelationIDFromContext returns the correlation ID from ctx, or empty string if it is not set
+func CorrelationIDFromContext(ctx context.Context) string {
+	ids, ok := ctx.Value(requestIDsCtxKey{}).(*requestIDs)
+	if !ok {
+		return ""
+	}
+	return ids.correlationID
+}
+
+// isValidRequestID checks if the ID from header is non-empty, not too long and contains printable ASCII only
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > requestIDMaxLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e || id[i] == '"' || id[i] == '\\' {
+			return false
+		}
+	}
+	return true
+}
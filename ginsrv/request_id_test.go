@@ -0,0 +1,104 @@
+package ginsrv
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/proactiongo/pagocore/i18n"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewares_RequestID(t *testing.T) {
+	b := &di.Builder{}
+	err := b.Add(di.Def{
+		Name: "pa_i18n",
+		Build: func(ctn *di.Container) (interface{}, error) {
+			return i18n.Source, nil
+		},
+	}, di.Def{
+		Name:  "test_request_scoped",
+		Scope: di.ScopeRequest,
+		Build: func(ctn *di.Container) (interface{}, error) {
+			return RequestIDFromContext(ctn.Context()), nil
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var ctxRequestID, ctxCorrelationID, logRequestID, scopedRequestID string
+	router := GetDefaultRouter()
+	router.Use(M().SetDIContainer(ctn))
+	router.GET("/ok", func(c *gin.Context) {
+		ctx := NewContextHandler(c)
+		ctxRequestID = RequestIDFromContext(c.Request.Context())
+		ctxCorrelationID = CorrelationIDFromContext(c.Request.Context())
+		logRequestID, _ = ctx.Log().Data[pagocore.LogFieldRequestID].(string)
+		scopedRequestID, _ = ctx.GetContainer().Get("test_request_scoped").(string)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/err", func(c *gin.Context) {
+		NewContextHandler(c).Err(pagocore.ErrNotFound)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	requestID := w.Header().Get(HeaderRequestID)
+	assert.NotEmpty(t, requestID)
+	assert.Equal(t, requestID, w.Header().Get(HeaderCorrelationID))
+	assert.Equal(t, requestID, ctxRequestID)
+	assert.Equal(t, requestID, ctxCorrelationID)
+	assert.Equal(t, requestID, logRequestID)
+	assert.Equal(t, requestID, scopedRequestID, "request-scoped dependencies must get the request context")
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set(HeaderRequestID, "upstream-request")
+	req.Header.Set(HeaderCorrelationID, "upstream-correlation")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "upstream-request", w.Header().Get(HeaderRequestID))
+	assert.Equal(t, "upstream-correlation", ctxCorrelationID)
+
+	req = httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set(HeaderRequestID, "invalid id "+strings.Repeat("x", requestIDMaxLen))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.NotContains(t, w.Header().Get(HeaderRequestID), "invalid")
+
+	req = httptest.NewRequest(http.MethodGet, "/err", nil)
+	req.Header.Set(HeaderRequestID, "failed-request")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	e := &pagocore.Error{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), e))
+	assert.Equal(t, http.StatusNotFound, e.Code)
+	assert.Equal(t, "failed-request", e.RequestID)
+	assert.Empty(t, pagocore.ErrNotFound.RequestID, "shared errors must not be modified")
+}
+
+func TestMiddlewares_LogFormatter_RequestID(t *testing.T) {
+	line := M().LogFormatter(gin.LogFormatterParams{
+		Request:    httptest.NewRequest(http.MethodGet, "/ok", nil),
+		TimeStamp:  time.Now(),
+		StatusCode: http.StatusOK,
+		Keys: map[string]interface{}{
+			KeyRequestID:     "req",
+			KeyCorrelationID: "corr",
+		},
+	})
+
+	data := map[string]string{}
+	assert.NoError(t, json.Unmarshal([]byte(line), &data))
+	assert.Equal(t, "req", data[pagocore.LogFieldRequestID])
+	assert.Equal(t, "corr", data[pagocore.LogFieldCorrelationID])
+}
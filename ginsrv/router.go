@@ -3,7 +3,6 @@ package ginsrv
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/proactiongo/pagocore"
-	log "github.com/sirupsen/logrus"
 	"net/http"
 )
 
@@ -13,10 +12,13 @@ func GetDefaultRouter() *gin.Engine {
 
 	router := gin.New()
 
+	// Request and correlation IDs for logs and error responses
+	router.Use(M().RequestID())
+
 	// Recover panics with formatted log
 	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
 		ctx := NewContextHandler(c)
-		log.Error(recovered)
+		ctx.Log().Error(recovered)
 		if s, ok := recovered.(string); ok {
 			ctx.ErrS(s, http.StatusInternalServerError)
 		}
@@ -0,0 +1,58 @@
+package ginsrv
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/tracing"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewares_Tracing(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tracing.SetGlobal(tracing.NewTracerProvider(nil, 1, sdktrace.WithSyncer(exp)))
+
+	var entry *log.Entry
+	router := gin.New()
+	router.Use(M().RequestID(), M().Tracing())
+	router.GET("/items/:id", func(c *gin.Context) {
+		entry = NewContextHandler(c).Log()
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(HeaderRequestID, "traced-request")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exp.GetSpans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	span := spans[0]
+	assert.Equal(t, "GET /items/:id", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Equal(t, "Error", span.Status.Code.String())
+
+	attrs := map[string]string{}
+	for _, kv := range span.Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, "/items/:id", attrs["http.route"])
+	assert.Equal(t, "500", attrs["http.status_code"])
+	assert.Equal(t, "traced-request", attrs[pagocore.LogFieldRequestID])
+
+	if assert.NotNil(t, entry) {
+		assert.NoError(t, (&pagocore.LogDftFieldsHook{}).Fire(entry))
+		assert.Equal(t, span.SpanContext.TraceID().String(), entry.Data[pagocore.LogFieldTraceID])
+		assert.Equal(t, span.SpanContext.SpanID().String(), entry.Data[pagocore.LogFieldSpanID])
+	}
+}
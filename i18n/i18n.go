@@ -3,6 +3,7 @@ package i18n
 import (
 	"regexp"
 	"strings"
+	"sync"
 )
 
 // Available languages
@@ -11,12 +12,31 @@ const (
 	LangEn = Language("en")
 )
 
-// Source is a current texts source
+// Source is a current texts source.
+// Use GetSource and SetSource if it is replaced while serving, e. g. on the config reload.
 var Source = &TextsSource{
 	DefaultLang:  LangEn,
 	Translations: make(Translations),
 }
 
+// sourceMu guards Source replacement
+var sourceMu sync.RWMutex
+
+// GetSource returns the current texts source, safe for concurrent use with SetSource
+func GetSource() *TextsSource {
+	sourceMu.RLock()
+	defer sourceMu.RUnlock()
+	return Source
+}
+
+// SetSource replaces the current texts source, safe for concurrent use with GetSource.
+// The previous source is not modified.
+func SetSource(src *TextsSource) {
+	sourceMu.Lock()
+	Source = src
+	sourceMu.Unlock()
+}
+
 var langCodeRegx *regexp.Regexp
 
 // Language is a language code
@@ -51,5 +71,5 @@ func (l *Language) String() string {
 
 // T is a Translations.GetText alias
 func T(textOrKey string, lang Language, tplData interface{}) string {
-	return Source.T(textOrKey, lang, tplData)
+	return GetSource().T(textOrKey, lang, tplData)
 }
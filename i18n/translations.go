@@ -19,8 +19,9 @@ func (t Translations) GetText(textOrKey string, lang Language, tplData interface
 
 // getTranslation returns Translation object for the specified textOrKey in specified lang
 func (t Translations) getTranslation(textOrKey string, lang Language) Translation {
+	dftLang := GetDefaultLang()
 	if lang == "" {
-		lang = Source.DefaultLang
+		lang = dftLang
 	}
 	dft := Translation{
 		Text: textOrKey,
@@ -35,8 +36,8 @@ func (t Translations) getTranslation(textOrKey string, lang Language) Translatio
 	if ok {
 		return text
 	}
-	if lang != Source.DefaultLang {
-		text, ok := texts[Source.DefaultLang]
+	if lang != dftLang {
+		text, ok := texts[dftLang]
 		if ok {
 			return text
 		}
@@ -7,7 +7,7 @@ import (
 
 // GetDefaultLang returns default Language
 func GetDefaultLang() Language {
-	return Source.DefaultLang
+	return GetSource().DefaultLang
 }
 
 // ParseLanguage parses Language from inp (Language, string, []byte or fmt.Stringer)
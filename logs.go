@@ -3,6 +3,7 @@ package pagocore
 import (
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"os"
 )
@@ -22,6 +23,10 @@ const (
 	LogFieldMethod            = "method"
 	LogFieldClientAppVersion  = "client_app_ver"
 	LogFieldClientAppPlatform = "client_app_platform"
+	LogFieldRequestID         = "request_id"
+	LogFieldCorrelationID     = "correlation_id"
+	LogFieldTraceID           = "trace_id"
+	LogFieldSpanID            = "span_id"
 )
 
 // Log types
@@ -82,6 +87,14 @@ func (h *LogDftFieldsHook) Fire(e *log.Entry) error {
 	e.Data[LogFieldService] = Opt.ServiceName
 	e.Data[LogFieldHostname] = Opt.GetHostname()
 	e.Data[LogFieldAPIVersion] = Opt.APIVersion
+
+	if e.Context != nil {
+		sc := trace.SpanContextFromContext(e.Context)
+		if sc.IsValid() {
+			e.Data[LogFieldTraceID] = sc.TraceID().String()
+			e.Data[LogFieldSpanID] = sc.SpanID().String()
+		}
+	}
 	return nil
 }
 
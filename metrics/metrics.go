@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Metric labels values
+const (
+	// PathUnmatched is a path label of the requests not matched by any route
+	PathUnmatched = "unmatched"
+
+	ResultOk       = "ok"
+	ResultNotFound = "not_found"
+	ResultError    = "error"
+)
+
+// Registry is a registry of pagocore metrics, Go runtime and process collectors.
+// Register service-specific metrics here to expose them by Handler.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests.",
+	}, []string{"path", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"path", "method", "status"})
+
+	httpInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests being served.",
+	}, []string{"path", "method"})
+
+	daoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dao_operation_duration_seconds",
+		Help:    "DAO operation latency.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"collection", "operation", "result"})
+
+	diBuildDuration = prometheus.NewDesc(
+		"di_build_duration_seconds",
+		"DI dependency build duration including dependencies built during the build.",
+		[]string{"name", "result"}, nil,
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		httpInFlight,
+		daoDuration,
+		containers,
+	)
+}
+
+// Handler returns HTTP handler exposing the Registry in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// HTTPRequestStarted increments in-flight requests gauge, the returned function records the finished request
+func HTTPRequestStarted(path, method string) func(status int) {
+	if path == "" {
+		path = PathUnmatched
+	}
+	inFlight := httpInFlight.WithLabelValues(path, method)
+	inFlight.Inc()
+	start := time.Now()
+
+	return func(status int) {
+		inFlight.Dec()
+		code := strconv.Itoa(status)
+		httpRequests.WithLabelValues(path, method, code).Inc()
+		httpDuration.WithLabelValues(path, method, code).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveDAO records duration of the DAO operation started at start
+func ObserveDAO(collection, operation string, start time.Time, err error) {
+	daoDuration.WithLabelValues(collection, operation, result(err)).Observe(time.Since(start).Seconds())
+}
+
+// result returns the result label value of the error
+func result(err error) string {
+	switch {
+	case err == nil:
+		return ResultOk
+	case errors.Is(err, pagocore.ErrNotFound):
+		return ResultNotFound
+	default:
+		return ResultError
+	}
+}
+
+// containers exposes build durations of the observed container
+var containers = &containerCollector{}
+
+// ObserveContainer exposes build durations of the ctn dependencies.
+// Only one container is observed, the previous one is replaced.
+func ObserveContainer(ctn *di.Container) {
+	containers.mu.Lock()
+	containers.ctn = ctn
+	containers.mu.Unlock()
+}
+
+// containerCollector collects di_build_duration_seconds from the container graph on scrape
+type containerCollector struct {
+	mu  sync.Mutex
+	ctn *di.Container
+}
+
+// Describe sends the collector metrics descriptors, see prometheus.Collector
+func (c *containerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- diBuildDuration
+}
+
+// Collect sends build durations of the built dependencies, see prometheus.Collector
+func (c *containerCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	ctn := c.ctn
+	c.mu.Unlock()
+	if ctn == nil {
+		return
+	}
+
+	for _, node := range ctn.Graph().Nodes {
+		if !node.Built {
+			continue
+		}
+		res := ResultOk
+		if node.Error != "" {
+			res = ResultError
+		}
+		ch <- prometheus.MustNewConstMetric(diBuildDuration, prometheus.GaugeValue, node.BuildDuration.Seconds(), node.Name, res)
+	}
+}
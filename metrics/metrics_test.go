@@ -0,0 +1,72 @@
+package metrics_test
+
+import (
+	"errors"
+	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/di"
+	"github.com/proactiongo/pagocore/metrics"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	done := metrics.HTTPRequestStarted("/items/:id", http.MethodGet)
+	assert.Contains(t, scrape(t), `http_requests_in_flight{method="GET",path="/items/:id"} 1`)
+	done(http.StatusOK)
+	metrics.HTTPRequestStarted("", http.MethodPost)(http.StatusNotFound)
+
+	start := time.Now()
+	metrics.ObserveDAO("users", "find_one", start, nil)
+	metrics.ObserveDAO("users", "find_one", start, pagocore.ErrNotFound)
+	metrics.ObserveDAO("users", "insert_one", start, errors.New("expected error"))
+
+	b := &di.Builder{}
+	err := b.Add(di.Def{
+		Name: "dep",
+		Build: func(ctn *di.Container) (interface{}, error) {
+			return "dep", nil
+		},
+	}, di.Def{
+		Name: "lazy",
+		Lazy: true,
+		Build: func(ctn *di.Container) (interface{}, error) {
+			return "lazy", nil
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctn, err := b.Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	metrics.ObserveContainer(ctn)
+
+	out := scrape(t)
+	assert.Contains(t, out, `http_requests_in_flight{method="GET",path="/items/:id"} 0`)
+	assert.Contains(t, out, `http_requests_total{method="GET",path="/items/:id",status="200"} 1`)
+	assert.Contains(t, out, `http_requests_total{method="POST",path="unmatched",status="404"} 1`)
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",path="/items/:id",status="200"} 1`)
+	assert.Contains(t, out, `dao_operation_duration_seconds_count{collection="users",operation="find_one",result="ok"} 1`)
+	assert.Contains(t, out, `dao_operation_duration_seconds_count{collection="users",operation="find_one",result="not_found"} 1`)
+	assert.Contains(t, out, `dao_operation_duration_seconds_count{collection="users",operation="insert_one",result="error"} 1`)
+	assert.Contains(t, out, `di_build_duration_seconds{name="dep",result="ok"}`)
+	assert.NotContains(t, out, `di_build_duration_seconds{name="lazy"`)
+	assert.Contains(t, out, "go_goroutines")
+}
+
+// scrape returns metrics in Prometheus text format
+func scrape(t *testing.T) string {
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	b, err := io.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -2,12 +2,18 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"github.com/proactiongo/pagocore"
+	"github.com/proactiongo/pagocore/metrics"
+	"github.com/proactiongo/pagocore/tracing"
 	log "github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 	"time"
 )
 
@@ -44,16 +50,28 @@ func NewDAOMg(collection *mongo.Collection) *DAOMg {
 
 // DAOMg is a mongo collection abstraction
 type DAOMg struct {
-	c *mongo.Collection
+	c   *mongo.Collection
+	ctx context.Context
+}
+
+// WithContext returns a copy of the DAO which operations are bound to ctx, e. g. to the request context.
+// Operations spans are children of the ctx span, ctx cancellation cancels the operations.
+func (d *DAOMg) WithContext(ctx context.Context) *DAOMg {
+	dao := *d
+	dao.ctx = ctx
+	return &dao
 }
 
 // FetchByID fetches row by ID to the target
-func (d *DAOMg) FetchByID(id string, target interface{}, opts ...*options.FindOneOptions) error {
+func (d *DAOMg) FetchByID(id string, target interface{}, opts ...*options.FindOneOptions) (err error) {
+	filter := bson.M{"_id": id}
+
 	ctx, cancel := d.Ctx(1)
 	defer cancel()
+	ctx, end := d.start(ctx, "find_one", filter)
+	defer end(&err)
 
-	filter := bson.M{"_id": id}
-	err := d.C().FindOne(ctx, filter, opts...).Decode(target)
+	err = d.C().FindOne(ctx, filter, opts...).Decode(target)
 
 	if err != nil {
 		return d.Err(err)
@@ -75,11 +93,13 @@ func (d *DAOMg) FetchByExIDs(ids []string, target interface{}, opts ...*options.
 }
 
 // FetchOne fetches one row by the filter
-func (d *DAOMg) FetchOne(target interface{}, filter interface{}, opts ...*options.FindOneOptions) error {
+func (d *DAOMg) FetchOne(target interface{}, filter interface{}, opts ...*options.FindOneOptions) (err error) {
 	ctx, cancel := d.Ctx(1)
 	defer cancel()
+	ctx, end := d.start(ctx, "find_one", filter)
+	defer end(&err)
 
-	err := d.C().FindOne(ctx, filter, opts...).Decode(target)
+	err = d.C().FindOne(ctx, filter, opts...).Decode(target)
 
 	if err != nil {
 		return d.Err(err)
@@ -94,9 +114,11 @@ func (d *DAOMg) FetchAll(target interface{}, opts ...*options.FindOptions) error
 }
 
 // FetchAllF fetches all rows from cursor with filter to the target
-func (d *DAOMg) FetchAllF(target interface{}, filter interface{}, opts ...*options.FindOptions) error {
+func (d *DAOMg) FetchAllF(target interface{}, filter interface{}, opts ...*options.FindOptions) (err error) {
 	ctx, cancel := d.Ctx(10)
 	defer cancel()
+	ctx, end := d.start(ctx, "find", filter)
+	defer end(&err)
 
 	cur, err := d.C().Find(ctx, filter, opts...)
 	if err != nil {
@@ -120,6 +142,8 @@ func (d *DAOMg) FetchAllF(target interface{}, filter interface{}, opts ...*optio
 func (d *DAOMg) InsertOne(data interface{}, opts ...*options.InsertOneOptions) (id string, err error) {
 	ctx, cancel := d.Ctx(3)
 	defer cancel()
+	ctx, end := d.start(ctx, "insert_one", nil)
+	defer end(&err)
 
 	doc, err := bson.Marshal(data)
 	if err != nil {
@@ -148,6 +172,8 @@ func (d *DAOMg) InsertOne(data interface{}, opts ...*options.InsertOneOptions) (
 func (d *DAOMg) InsertMany(rows []interface{}, opts ...*options.InsertManyOptions) (insertedIDs []string, err error) {
 	ctx, cancel := d.Ctx(30)
 	defer cancel()
+	ctx, end := d.start(ctx, "insert_many", nil)
+	defer end(&err)
 
 	docs := make([]interface{}, len(rows))
 	for i, row := range rows {
@@ -188,9 +214,11 @@ func (d *DAOMg) InsertMany(rows []interface{}, opts ...*options.InsertManyOption
 }
 
 // UpdateByID updates one row by ID
-func (d *DAOMg) UpdateByID(id string, data interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+func (d *DAOMg) UpdateByID(id string, data interface{}, opts ...*options.UpdateOptions) (res *mongo.UpdateResult, err error) {
 	ctx, cancel := d.Ctx(3)
 	defer cancel()
+	ctx, end := d.start(ctx, "update_one", bson.M{"_id": id})
+	defer end(&err)
 
 	upd := map[string]interface{}{
 		"$set": data,
@@ -204,9 +232,11 @@ func (d *DAOMg) UpdateByID(id string, data interface{}, opts ...*options.UpdateO
 }
 
 // UpdateOne updates one row
-func (d *DAOMg) UpdateOne(filter interface{}, data interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+func (d *DAOMg) UpdateOne(filter interface{}, data interface{}, opts ...*options.UpdateOptions) (res *mongo.UpdateResult, err error) {
 	ctx, cancel := d.Ctx(3)
 	defer cancel()
+	ctx, end := d.start(ctx, "update_one", filter)
+	defer end(&err)
 
 	upd := map[string]interface{}{
 		"$set": data,
@@ -226,16 +256,20 @@ func (d *DAOMg) DeleteByID(id string, opts ...*options.DeleteOptions) (*mongo.De
 }
 
 // DeleteOne deletes one row
-func (d *DAOMg) DeleteOne(filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+func (d *DAOMg) DeleteOne(filter interface{}, opts ...*options.DeleteOptions) (res *mongo.DeleteResult, err error) {
 	ctx, cancel := d.Ctx(3)
 	defer cancel()
+	ctx, end := d.start(ctx, "delete_one", filter)
+	defer end(&err)
 	return d.C().DeleteOne(ctx, filter, opts...)
 }
 
 // DeleteMany deletes filtered rows
-func (d *DAOMg) DeleteMany(filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+func (d *DAOMg) DeleteMany(filter interface{}, opts ...*options.DeleteOptions) (res *mongo.DeleteResult, err error) {
 	ctx, cancel := d.Ctx(5)
 	defer cancel()
+	ctx, end := d.start(ctx, "delete_many", filter)
+	defer end(&err)
 	return d.C().DeleteMany(ctx, filter, opts...)
 }
 
@@ -244,9 +278,13 @@ func (d *DAOMg) C() *mongo.Collection {
 	return d.c
 }
 
-// Ctx creates new timeout context
+// Ctx creates new timeout context, derived from the WithContext context if it is set
 func (d *DAOMg) Ctx(seconds uint) (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), time.Duration(seconds)*time.Second)
+	ctx := d.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
 }
 
 // Err transforms and log an error if needed
@@ -262,12 +300,45 @@ func (d *DAOMg) Err(err error) error {
 		needLog = false
 	}
 	if needLog {
-		cName := "_unknown_"
-		c := d.C()
-		if c != nil {
-			cName = c.Name()
-		}
-		log.WithField("dao", cName).Error(err)
+		log.WithField("dao", d.collectionName()).Error(err)
 	}
 	return err
 }
+
+// start starts the operation span with the filter shape as the statement if the span is recorded.
+// The returned function ends the span and records the operation duration metric, see metrics.ObserveDAO.
+func (d *DAOMg) start(ctx context.Context, operation string, filter interface{}) (context.Context, func(err *error)) {
+	collection := d.collectionName()
+	start := time.Now()
+
+	ctx, span := tracing.Tracer().Start(ctx, "mongodb."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMongoDB,
+			semconv.DBMongoDBCollectionKey.String(collection),
+			semconv.DBOperationKey.String(operation),
+		),
+	)
+	// the filter shape is marshalled only for sampled spans
+	if filter != nil && span.IsRecording() {
+		span.SetAttributes(semconv.DBStatementKey.String(FilterShape(filter)))
+	}
+
+	return ctx, func(err *error) {
+		if *err != nil && !errors.Is(*err, pagocore.ErrNotFound) {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+		metrics.ObserveDAO(collection, operation, start, *err)
+	}
+}
+
+// collectionName returns the collection name, or "_unknown_" if the collection is not set
+func (d *DAOMg) collectionName() string {
+	c := d.C()
+	if c == nil {
+		return "_unknown_"
+	}
+	return c.Name()
+}
@@ -0,0 +1,54 @@
+package mongodb
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"strconv"
+	"strings"
+)
+
+// filterValueMask replaces filter values in FilterShape
+const filterValueMask = `"?"`
+
+// FilterShape returns the filter as JSON with the values masked, e. g. {"_id":{"$in":"?"}}.
+// Nested documents and arrays of documents, e. g. in $or, keep their keys.
+func FilterShape(filter interface{}) string {
+	raw, err := bson.Marshal(filter)
+	if err != nil {
+		return filterValueMask
+	}
+	var doc bson.D
+	err = bson.Unmarshal(raw, &doc)
+	if err != nil {
+		return filterValueMask
+	}
+	return filterDocShape(doc)
+}
+
+// filterDocShape returns the document shape
+func filterDocShape(doc bson.D) string {
+	fields := make([]string, len(doc))
+	for i, e := range doc {
+		fields[i] = strconv.Quote(e.Key) + ":" + filterValueShape(e.Value)
+	}
+	return "{" + strings.Join(fields, ",") + "}"
+}
+
+// filterValueShape returns the value shape, only documents and arrays of documents are not masked
+func filterValueShape(v interface{}) string {
+	switch val := v.(type) {
+	case bson.D:
+		return filterDocShape(val)
+	case bson.A:
+		items := make([]string, 0, len(val))
+		for _, item := range val {
+			doc, ok := item.(bson.D)
+			if !ok {
+				return filterValueMask
+			}
+			items = append(items, filterDocShape(doc))
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	default:
+		return filterValueMask
+	}
+}
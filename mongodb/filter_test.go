@@ -0,0 +1,22 @@
+package mongodb_test
+
+import (
+	"github.com/proactiongo/pagocore/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestFilterShape(t *testing.T) {
+	assert.Equal(t, `{"_id":"?"}`, mongodb.FilterShape(bson.M{"_id": "secret"}))
+	assert.Equal(t, `{"_id":{"$in":"?"}}`, mongodb.FilterShape(bson.M{"_id": bson.M{"$in": []string{"a", "b"}}}))
+	assert.Equal(t,
+		`{"$or":[{"email":"?"},{"phone":"?"}],"active":"?"}`,
+		mongodb.FilterShape(bson.D{
+			{Key: "$or", Value: bson.A{bson.M{"email": "a@b.c"}, bson.M{"phone": "123"}}},
+			{Key: "active", Value: true},
+		}),
+	)
+	assert.Equal(t, `{}`, mongodb.FilterShape(bson.M{}))
+	assert.Equal(t, `"?"`, mongodb.FilterShape("invalid"))
+}
@@ -59,6 +59,11 @@ type MongoDB struct {
 	Db     *mongo.Database
 }
 
+// Ping checks connection to the primary node
+func (m *MongoDB) Ping(ctx context.Context) error {
+	return m.client.Ping(ctx, readpref.Primary())
+}
+
 // Close closes db connection context
 func (m *MongoDB) Close() error {
 	if m.client != nil {
@@ -2,8 +2,9 @@ package pagocore
 
 import (
 	log "github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
 	"os"
+	"sync"
 )
 
 // Opt shares package options
@@ -22,10 +23,18 @@ var Opt = &Options{
 
 // Options represents package options
 type Options struct {
-	// ConfigFilePath is a path to an app config file
+	// ConfigFilePath is a path to an app config file, skipped if missing
 	ConfigFilePath string
-	// ConfigFileType is an app config file type, e. g. "env"
+	// ConfigFileType is an app config file type, e. g. "env", used if the file extension is unknown
 	ConfigFileType string
+	// ConfigExtraFiles are paths to config files applied over ConfigFilePath in order, skipped if missing
+	ConfigExtraFiles []string
+	// ConfigEnvPrefix is a prefix of environment variables overriding config values, e. g. "APP"
+	ConfigEnvPrefix string
+	// ConfigDefaults are default config values
+	ConfigDefaults map[string]interface{}
+	// ConfigFlags are parsed command-line flags overriding config values
+	ConfigFlags *pflag.FlagSet
 
 	// LogsPath is a path to the log files directory
 	LogsPath string
@@ -40,6 +49,10 @@ type Options struct {
 	APIVersion string
 	// APIBasePath is a service API base path, e. g. /api/v1
 	APIBasePath string
+	// HealthBasePath is a base path of /healthz and /readyz endpoints, APIBasePath if empty
+	HealthBasePath string
+	// HealthRoutesDisabled disables mounting of /healthz and /readyz endpoints by the App
+	HealthRoutesDisabled bool
 
 	// ServiceName is a service identifier
 	ServiceName string
@@ -51,8 +64,24 @@ type Options struct {
 	// Hostname of current node if is required to override os.Hostname() value
 	Hostname string
 
-	// JWTPassword is JWT password key
+	// JWTPassword is JWT password key, use GetJWTPassword and SetJWTPassword if it is changed while serving
 	JWTPassword []byte
+
+	mu sync.RWMutex
+}
+
+// GetJWTPassword returns JWTPassword, safe for concurrent use with SetJWTPassword
+func (o *Options) GetJWTPassword() []byte {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.JWTPassword
+}
+
+// SetJWTPassword sets JWTPassword, safe for concurrent use with GetJWTPassword
+func (o *Options) SetJWTPassword(password []byte) {
+	o.mu.Lock()
+	o.JWTPassword = password
+	o.mu.Unlock()
 }
 
 // GetHostname returns hostname from options or OS
@@ -63,6 +92,14 @@ func (o *Options) GetHostname() string {
 	return o.Hostname
 }
 
+// GetHealthBasePath returns base path of health endpoints
+func (o *Options) GetHealthBasePath() string {
+	if o.HealthBasePath == "" {
+		return o.APIBasePath
+	}
+	return o.HealthBasePath
+}
+
 // ServiceInfo represents info about the service
 type ServiceInfo struct {
 	Name        string `json:"name"`
@@ -82,18 +119,3 @@ func GetServiceInfo() *ServiceInfo {
 		Repo:        Opt.ServiceRepo,
 	}
 }
-
-// ReadConfig loads configuration from the config file to the viper instance
-func ReadConfig() (*viper.Viper, error) {
-	conf := viper.New()
-
-	conf.SetConfigFile(Opt.ConfigFilePath)
-	conf.SetConfigType(Opt.ConfigFileType)
-
-	err := conf.ReadInConfig()
-	if err != nil {
-		return nil, err
-	}
-
-	return conf, nil
-}
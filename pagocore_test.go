@@ -38,6 +38,7 @@ func TestReadConfig(t *testing.T) {
 	assert.Equal(t, "test2", conf.GetString("OTHER_TEST_ENV_VAR"))
 
 	pagocore.Opt.ConfigFilePath = "__unkown_file__.env"
-	_, err = pagocore.ReadConfig()
-	assert.Error(t, err)
+	conf, err = pagocore.ReadConfig()
+	assert.NoError(t, err)
+	assert.False(t, conf.IsSet("test_env_var"))
 }
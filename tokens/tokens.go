@@ -29,7 +29,7 @@ func ParseAccessToken(tokenContent string) (*AccessTokenClaims, error) {
 
 // parseJWTKeyFunc returns JWT password key
 func parseJWTKeyFunc(_ *jwt.Token) (interface{}, error) {
-	return pagocore.Opt.JWTPassword, nil
+	return pagocore.Opt.GetJWTPassword(), nil
 }
 
 // TokenClaims is token claims interface
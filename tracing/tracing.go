@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/proactiongo/pagocore"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+)
+
+// InstrumentationName is a name of the pagocore tracer
+const InstrumentationName = "github.com/proactiongo/pagocore"
+
+// Exporters
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Options are the tracing options
+type Options struct {
+	// Exporter is one of ExporterNone, ExporterStdout or ExporterOTLP
+	Exporter string
+
+	// OTLPEndpoint is the OTLP/HTTP collector host:port, OTEL_EXPORTER_OTLP_* environment variables are used if empty
+	OTLPEndpoint string
+
+	// OTLPInsecure disables TLS for the OTLP exporter
+	OTLPInsecure bool
+
+	// SampleRatio is a ratio of traces sampled if there is no sampled parent span, 0 to 1
+	SampleRatio float64
+}
+
+// Tracer returns the pagocore tracer of the global tracer provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// NewExporter creates the span exporter by opt.Exporter, nil is returned for ExporterNone
+func NewExporter(ctx context.Context, opt *Options) (sdktrace.SpanExporter, error) {
+	switch opt.Exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		var otlpOpts []otlptracehttp.Option
+		if opt.OTLPEndpoint != "" {
+			otlpOpts = append(otlpOpts, otlptracehttp.WithEndpoint(opt.OTLPEndpoint))
+		}
+		if opt.OTLPInsecure {
+			otlpOpts = append(otlpOpts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, otlpOpts...)
+	default:
+		return nil, errors.New("[pagocore.tracing] unsupported exporter: " + opt.Exporter)
+	}
+}
+
+// NewTracerProvider creates the tracer provider batching spans to exp.
+// Use tracetest.NewInMemoryExporter() with sdktrace.WithSyncer to assert spans in tests.
+func NewTracerProvider(exp sdktrace.SpanExporter, sampleRatio float64, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(pagocore.Opt.ServiceName),
+		semconv.ServiceVersionKey.String(pagocore.Opt.APIVersion),
+		semconv.HostNameKey.String(pagocore.Opt.GetHostname()),
+	)
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	}, opts...)
+	if exp != nil {
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// SetGlobal sets the global tracer provider and W3C trace context and baggage propagator
+func SetGlobal(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// Init creates the exporter and the tracer provider by opt and sets them global.
+// The returned provider must be shut down to flush the spans.
+func Init(ctx context.Context, opt *Options) (*sdktrace.TracerProvider, error) {
+	exp, err := NewExporter(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	tp := NewTracerProvider(exp, opt.SampleRatio)
+	SetGlobal(tp)
+	return tp, nil
+}
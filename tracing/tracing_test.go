@@ -0,0 +1,68 @@
+package tracing_test
+
+import (
+	"context"
+	"github.com/proactiongo/pagocore/tracing"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewExporter(t *testing.T) {
+	exp, err := tracing.NewExporter(context.Background(), &tracing.Options{})
+	assert.NoError(t, err)
+	assert.Nil(t, exp)
+
+	exp, err = tracing.NewExporter(context.Background(), &tracing.Options{Exporter: tracing.ExporterStdout})
+	assert.NoError(t, err)
+	assert.NotNil(t, exp)
+
+	exp, err = tracing.NewExporter(context.Background(), &tracing.Options{Exporter: tracing.ExporterOTLP, OTLPEndpoint: "localhost:4318"})
+	assert.NoError(t, err)
+	assert.NotNil(t, exp)
+
+	_, err = tracing.NewExporter(context.Background(), &tracing.Options{Exporter: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestNewTransport(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := tracing.NewTracerProvider(nil, 1, sdktrace.WithSyncer(exp))
+	tracing.SetGlobal(tp)
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "parent")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp, err := (&http.Client{Transport: tracing.NewTransport(nil)}).Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+	parent.End()
+
+	spans := exp.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	client := spans[0]
+	assert.Equal(t, "HTTP GET", client.Name)
+	assert.Equal(t, trace.SpanKindClient, client.SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), client.Parent.SpanID())
+	assert.Contains(t, traceparent, client.SpanContext.TraceID().String())
+	assert.Contains(t, traceparent, client.SpanContext.SpanID().String())
+	assert.Equal(t, "Error", client.Status.Code.String())
+	assert.Empty(t, req.Header.Get("traceparent"), "original request must not be modified")
+}
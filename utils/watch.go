@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// watchDebounce is a time to collect file events before onChange call
+const watchDebounce = 100 * time.Millisecond
+
+// WatchFiles calls onChange when any of the files is written, created, removed or renamed.
+// Parent directories are watched, so the files may not exist on start and may be replaced
+// with the atomic rename or Kubernetes ConfigMap symlinks swap.
+// Returned stop function stops the watching and waits for the running onChange call.
+func WatchFiles(paths []string, onChange func()) (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+		files[abs] = true
+		dir := filepath.Dir(abs)
+		if !dirs[dir] {
+			if err := watcher.Add(dir); err != nil {
+				_ = watcher.Close()
+				return nil, err
+			}
+			dirs[dir] = true
+		}
+	}
+
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var timer <-chan time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case e, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Kubernetes updates mounted files by swapping the ..data symlink
+				if files[filepath.Clean(e.Name)] || filepath.Base(e.Name) == "..data" {
+					timer = time.After(watchDebounce)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-timer:
+				timer = nil
+				onChange()
+			}
+		}
+	}()
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			_ = watcher.Close()
+		})
+	}, nil
+}
@@ -0,0 +1,45 @@
+package utils_test
+
+import (
+	"github.com/proactiongo/pagocore/utils"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.env")
+	other := filepath.Join(dir, "other.env")
+
+	changes := make(chan struct{}, 10)
+	stop, err := utils.WatchFiles([]string{path}, func() {
+		changes <- struct{}{}
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stop()
+
+	assert.NoError(t, os.WriteFile(other, []byte("A=1"), 0644))
+	assert.NoError(t, os.WriteFile(path, []byte("A=1"), 0644))
+	assert.NoError(t, os.WriteFile(path, []byte("A=2"), 0644))
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("change is not detected")
+	}
+
+	select {
+	case <-changes:
+		t.Fatal("changes are not debounced")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	stop()
+	_, err = utils.WatchFiles([]string{filepath.Join(dir, "missing", "config.env")}, func() {})
+	assert.Error(t, err)
+}